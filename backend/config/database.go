@@ -57,12 +57,23 @@ func AutoMigrate() error {
 	// Import all models here
 	models := []interface{}{
 		&models.Driver{},
+		&models.DriverSeason{},
+		&models.DriverRating{},
+		&models.RacePrediction{},
+		&models.PredictedPosition{},
 		&models.Team{},
+		&models.TeamSeason{},
 		&models.Race{},
 		&models.Circuit{},
 		&models.RaceDriver{},
+		&models.QualifyingResult{},
 		&models.RaceTeam{},
 		&models.Lap{},
+		&models.Stint{},
+		&models.PositionSample{},
+		&models.IntervalSample{},
+		&models.RaceControlMessage{},
+		&models.CarDataSample{},
 	}
 
 	// Run migrations
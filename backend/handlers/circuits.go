@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/f1-analytics/models"
+	"github.com/f1-analytics/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CircuitHandler struct {
+	openF1Service OpenF1Service
+	db            *gorm.DB
+}
+
+func NewCircuitHandler(openF1Service OpenF1Service, db *gorm.DB) *CircuitHandler {
+	return &CircuitHandler{
+		openF1Service: openF1Service,
+		db:            db,
+	}
+}
+
+// GetOvertakingDifficulty ranks circuits by how hard it is to pass, over every
+// race with stored positions
+func (h *CircuitHandler) GetOvertakingDifficulty(c *gin.Context) {
+	var races []models.Race
+	if err := h.db.Where("id IN (?)", h.db.Model(&models.PositionSample{}).Distinct("race_id")).
+		Find(&races).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch races from database",
+		})
+		return
+	}
+
+	overtakes := make([]services.RaceOvertakes, 0, len(races))
+	for _, race := range races {
+		raceOvertakes, err := loadRaceOvertakes(h.db, race)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch positions from database",
+			})
+			return
+		}
+		overtakes = append(overtakes, raceOvertakes)
+	}
+
+	var circuits []models.Circuit
+	if err := h.db.Find(&circuits).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch circuits from database",
+		})
+		return
+	}
+	names := make(map[uint]string, len(circuits))
+	for _, circuit := range circuits {
+		names[circuit.ID] = circuit.Name
+	}
+
+	type CircuitOvertakingResponse struct {
+		services.CircuitOvertaking
+		Name string `json:"name"`
+	}
+
+	difficulty := services.OvertakingDifficulty(overtakes)
+	response := make([]CircuitOvertakingResponse, len(difficulty))
+	for i, circuit := range difficulty {
+		response[i] = CircuitOvertakingResponse{
+			CircuitOvertaking: circuit,
+			Name:              names[circuit.CircuitID],
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"races":    len(overtakes),
+		"circuits": response,
+	})
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/f1-analytics/models"
+	"github.com/f1-analytics/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CompareHandler struct {
+	openF1Service OpenF1Service
+	db            *gorm.DB
+}
+
+func NewCompareHandler(openF1Service OpenF1Service, db *gorm.DB) *CompareHandler {
+	return &CompareHandler{
+		openF1Service: openF1Service,
+		db:            db,
+	}
+}
+
+// CompareDrivers returns the head-to-head between two drivers over a season
+func (h *CompareHandler) CompareDrivers(c *gin.Context) {
+	numberA, errA := strconv.Atoi(c.Query("a"))
+	numberB, errB := strconv.Atoi(c.Query("b"))
+	if errA != nil || errB != nil || numberA == numberB {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Two different driver numbers are required",
+		})
+		return
+	}
+
+	year := services.GetCurrentSeason()
+	if seasonStr := c.Query("season"); seasonStr != "" {
+		var err error
+		year, err = strconv.Atoi(seasonStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid season",
+			})
+			return
+		}
+	}
+
+	var driverA, driverB models.Driver
+	if err := h.db.First(&driverA, "number = ?", numberA).Error; err != nil {
+		respondDriverLookupError(c, err)
+		return
+	}
+	if err := h.db.First(&driverB, "number = ?", numberB).Error; err != nil {
+		respondDriverLookupError(c, err)
+		return
+	}
+
+	season, err := loadSeasonResults(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch season results from database",
+		})
+		return
+	}
+	if len(season.Races) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Season not found",
+		})
+		return
+	}
+
+	rounds, err := comparisonRounds(h.db, season, driverA.ID, driverB.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch comparison data from database",
+		})
+		return
+	}
+
+	drivers, err := loadSeasonDrivers(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch drivers from database",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"season":     year,
+		"driver_a":   drivers[driverA.ID],
+		"driver_b":   drivers[driverB.ID],
+		"comparison": services.CompareDrivers(rounds),
+	})
+}
+
+// comparisonRounds gathers both drivers' results, qualifying and laps for every
+// round of a season
+func comparisonRounds(db *gorm.DB, season services.SeasonResults, driverA, driverB uint) ([]services.ComparisonRound, error) {
+	raceIDs := make([]uint, len(season.Races))
+	for i, race := range season.Races {
+		raceIDs[i] = race.ID
+	}
+
+	var qualifying []models.QualifyingResult
+	if err := db.Where("race_id IN ? AND driver_id IN ?", raceIDs, []uint{driverA, driverB}).Find(&qualifying).Error; err != nil {
+		return nil, err
+	}
+	qualiPositions := make(map[uint]map[uint]int)
+	for _, q := range qualifying {
+		if qualiPositions[q.RaceID] == nil {
+			qualiPositions[q.RaceID] = make(map[uint]int)
+		}
+		qualiPositions[q.RaceID][q.DriverID] = q.Position
+	}
+
+	var laps []models.Lap
+	if err := db.Where("race_id IN ? AND driver_id IN ? AND session = ?", raceIDs, []uint{driverA, driverB}, models.SessionRace).
+		Order("lap_number").Find(&laps).Error; err != nil {
+		return nil, err
+	}
+	lapsByRace := make(map[uint]map[uint][]models.Lap)
+	for _, lap := range laps {
+		if lapsByRace[lap.RaceID] == nil {
+			lapsByRace[lap.RaceID] = make(map[uint][]models.Lap)
+		}
+		lapsByRace[lap.RaceID][lap.DriverID] = append(lapsByRace[lap.RaceID][lap.DriverID], lap)
+	}
+
+	side := func(race models.Race, driverID uint) services.ComparisonSide {
+		for _, result := range season.Results[race.ID] {
+			if result.DriverID != driverID {
+				continue
+			}
+			return services.ComparisonSide{
+				Present:       true,
+				QualiPosition: qualiPositions[race.ID][driverID],
+				Grid:          result.Grid,
+				Position:      result.Position,
+				Points:        result.Points + result.SprintPoints,
+				Status:        result.Status,
+				Laps:          lapsByRace[race.ID][driverID],
+			}
+		}
+		return services.ComparisonSide{}
+	}
+
+	rounds := make([]services.ComparisonRound, 0, len(season.Races))
+	for _, race := range season.Races {
+		rounds = append(rounds, services.ComparisonRound{
+			Round:  race.Round,
+			RaceID: race.ID,
+			Race:   race.Name,
+			A:      side(race, driverA),
+			B:      side(race, driverB),
+		})
+	}
+	return rounds, nil
+}
+
+// respondDriverLookupError reports a failed lookup of a driver by number
+func respondDriverLookupError(c *gin.Context, err error) {
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Driver not found",
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": "Failed to fetch driver from database",
+	})
+}
@@ -3,10 +3,14 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/f1-analytics/models"
+	"github.com/f1-analytics/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type DriverHandler struct {
@@ -23,25 +27,29 @@ func NewDriverHandler(openF1Service OpenF1Service, db *gorm.DB) *DriverHandler {
 
 // GetDrivers returns all F1 drivers with optional filtering
 func (h *DriverHandler) GetDrivers(c *gin.Context) {
-	var drivers []models.Driver
-	result := h.db.Find(&drivers)
-	if result.Error != nil {
+	// Parse query parameters
+	var season *int
+	if seasonStr := c.Query("season"); seasonStr != "" {
+		if s, err := strconv.Atoi(seasonStr); err == nil {
+			season = &s
+		}
+	}
+
+	year := services.GetCurrentSeason()
+	if season != nil {
+		year = *season
+	}
+
+	var stored int64
+	if err := h.db.Model(&models.DriverSeason{}).Where("season = ?", year).Count(&stored).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to fetch drivers from database",
 		})
 		return
 	}
 
-	// If no drivers in database, fetch from OpenF1 API and store them
-	if len(drivers) == 0 {
-		// Parse query parameters
-		var season *int
-		if seasonStr := c.Query("season"); seasonStr != "" {
-			if s, err := strconv.Atoi(seasonStr); err == nil {
-				season = &s
-			}
-		}
-
+	// If no drivers for the season in database, fetch from OpenF1 API and store them
+	if stored == 0 {
 		var meetingKey *int
 		if meetingKeyStr := c.Query("meeting_key"); meetingKeyStr != "" {
 			if mk, err := strconv.Atoi(meetingKeyStr); err == nil {
@@ -56,59 +64,193 @@ func (h *DriverHandler) GetDrivers(c *gin.Context) {
 			}
 		}
 
-		var teamName *string
-		if team := c.Query("team"); team != "" {
-			teamName = &team
-		}
-
-		apiDrivers, err := h.openF1Service.GetDrivers(season, meetingKey, sessionKey, teamName)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to fetch drivers from API",
-			})
-			return
+		// A requested season's field comes from its latest race rather than the
+		// current session, and there is nothing to fetch before its first race
+		if sessionKey == nil && meetingKey == nil && season != nil {
+			sk, err := latestRaceSessionKey(h.openF1Service, year)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to fetch sessions from API",
+				})
+				return
+			}
+			sessionKey = sk
 		}
 
-		// Convert API drivers to database models
-		for _, apiDriver := range apiDrivers {
-			driver := models.Driver{
-				Number:      apiDriver.DriverNumber,
-				Name:        apiDriver.BroadcastName,
-				Nationality: apiDriver.CountryCode,
-				Active:      true,
-				// Set other fields as needed
+		if sessionKey != nil || meetingKey != nil || season == nil {
+			apiDrivers, err := h.openF1Service.GetDrivers(nil, meetingKey, sessionKey, nil)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to fetch drivers from API",
+				})
+				return
 			}
-			if err := h.db.Create(&driver).Error; err != nil {
+			if _, err := storeDrivers(h.db, apiDrivers, year); err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{
 					"error": "Failed to store drivers in database",
 				})
 				return
 			}
-			drivers = append(drivers, driver)
 		}
 	}
 
+	// A requested season lists only the drivers entered for it
+	query := h.db.Preload("Team").Preload("Seasons.Team")
+	if season != nil {
+		query = query.Where("id IN (?)", h.db.Model(&models.DriverSeason{}).Select("driver_id").Where("season = ?", *season))
+	}
+
+	var drivers []models.Driver
+	result := query.Find(&drivers)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch drivers from database",
+		})
+		return
+	}
+
 	// Transform drivers to match expected response format
 	type DriverResponse struct {
 		DriverNumber int    `json:"driver_number"`
 		Name         string `json:"name"`
+		FirstName    string `json:"first_name"`
+		LastName     string `json:"last_name"`
+		FullName     string `json:"full_name"`
+		NameAcronym  string `json:"name_acronym"`
+		HeadshotURL  string `json:"headshot_url"`
 		Team         string `json:"team"`
+		TeamColor    string `json:"team_colour"`
 		Country      string `json:"country"`
 	}
 
-	response := make([]DriverResponse, len(drivers))
-	for i, driver := range drivers {
-		response[i] = DriverResponse{
+	response := make([]DriverResponse, 0, len(drivers))
+	for _, driver := range drivers {
+		entry := DriverResponse{
 			DriverNumber: driver.Number,
 			Name:         driver.Name,
+			FirstName:    driver.FirstName,
+			LastName:     driver.LastName,
+			FullName:     driver.FullName,
+			NameAcronym:  driver.NameAcronym,
+			HeadshotURL:  driver.HeadshotURL,
 			Team:         driver.Team.Name,
-			Country:      driver.Nationality,
+			TeamColor:    driver.Team.Color,
+			Country:      driver.CountryCode,
+		}
+
+		// Prefer the season-specific presentation data when a season was requested
+		if season != nil {
+			for _, ds := range driver.Seasons {
+				if ds.Season != *season {
+					continue
+				}
+				if ds.NameAcronym != "" {
+					entry.NameAcronym = ds.NameAcronym
+				}
+				if ds.HeadshotURL != "" {
+					entry.HeadshotURL = ds.HeadshotURL
+				}
+				if ds.Team.Name != "" {
+					entry.Team = ds.Team.Name
+				}
+				if ds.TeamColor != "" {
+					entry.TeamColor = ds.TeamColor
+				}
+			}
 		}
+
+		if team := c.Query("team"); team != "" && !strings.EqualFold(entry.Team, team) {
+			continue
+		}
+		response = append(response, entry)
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// latestRaceSessionKey returns the session key of the latest race of a season
+// to have started, or nil when none has
+func latestRaceSessionKey(openF1Service OpenF1Service, year int) (*int, error) {
+	sessions, err := openF1Service.GetSeasonSessions(year, models.SessionRace)
+	if err != nil {
+		return nil, err
+	}
+	var latest *services.Session
+	for i, session := range sessions {
+		if session.DateStart.After(time.Now()) {
+			continue
+		}
+		if latest == nil || session.DateStart.After(latest.DateStart) {
+			latest = &sessions[i]
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	return &latest.SessionKey, nil
+}
+
+// storeDrivers upserts the drivers OpenF1 listed for a session of a season,
+// matching them on their number, along with their team and season entry. A
+// driver's current team and headshot follow their latest season. The season
+// entries are returned by driver number.
+func storeDrivers(db *gorm.DB, apiDrivers []services.Driver, year int) (map[int]models.DriverSeason, error) {
+	seasons := make(map[int]models.DriverSeason, len(apiDrivers))
+	for _, apiDriver := range apiDrivers {
+		team, err := findOrCreateTeam(db, apiDriver.TeamName, apiDriver.TeamColor, year)
+		if err != nil {
+			return nil, err
+		}
+
+		driver := models.Driver{
+			Number:      apiDriver.DriverNumber,
+			Name:        apiDriver.BroadcastName,
+			FirstName:   apiDriver.FirstName,
+			LastName:    apiDriver.LastName,
+			FullName:    apiDriver.FullName,
+			NameAcronym: apiDriver.NameAcronym,
+			Nationality: apiDriver.CountryCode,
+			CountryCode: apiDriver.CountryCode,
+			HeadshotURL: apiDriver.HeadshotURL,
+			TeamID:      team.ID,
+			Active:      true,
+		}
+		err = db.Omit("Team").Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "number"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "first_name", "last_name", "full_name", "name_acronym", "country_code", "updated_at"}),
+		}).Create(&driver).Error
+		if err != nil {
+			return nil, err
+		}
+
+		var latest int
+		if err := db.Model(&models.DriverSeason{}).Where("driver_id = ?", driver.ID).Select("COALESCE(MAX(season), 0)").Scan(&latest).Error; err != nil {
+			return nil, err
+		}
+
+		driverSeason := models.DriverSeason{
+			DriverID:    driver.ID,
+			Season:      year,
+			TeamID:      team.ID,
+			NameAcronym: apiDriver.NameAcronym,
+			HeadshotURL: apiDriver.HeadshotURL,
+			TeamColor:   apiDriver.TeamColor,
+		}
+		if err := db.Omit("Team").Clauses(clause.OnConflict{UpdateAll: true}).Create(&driverSeason).Error; err != nil {
+			return nil, err
+		}
+		seasons[apiDriver.DriverNumber] = driverSeason
+
+		// Keep the driver's current team in line with their latest season
+		if year >= latest {
+			if err := db.Model(&models.Driver{}).Where("id = ?", driver.ID).Updates(models.Driver{TeamID: team.ID, HeadshotURL: apiDriver.HeadshotURL}).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+	return seasons, nil
+}
+
 // GetDriver returns a specific driver by ID
 func (h *DriverHandler) GetDriver(c *gin.Context) {
 	driverNumber, err := strconv.Atoi(c.Param("id"))
@@ -120,7 +262,7 @@ func (h *DriverHandler) GetDriver(c *gin.Context) {
 	}
 
 	var driver models.Driver
-	result := h.db.First(&driver, "number = ?", driverNumber)
+	result := h.db.Preload("Team").Preload("Seasons.Team").First(&driver, "number = ?", driverNumber)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -162,13 +304,31 @@ func (h *DriverHandler) GetDriverStats(c *gin.Context) {
 		return
 	}
 
-	stats := gin.H{
-		"wins":        driver.CareerWins,
-		"podiums":     driver.CareerPodiums,
-		"points":      driver.CareerPoints,
-		"poles":       driver.CareerPoles,
-		"fastestLaps": driver.CareerFastLaps,
+	span, err := parseSeasonRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Statistics are derived from the stored results rather than the career columns
+	seasons, err := loadSeasonRange(h.db, span)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch results from database",
+		})
+		return
+	}
+	entries, err := statEntries(h.db, seasons, func(result models.RaceDriver) bool {
+		return result.DriverID == driver.ID
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch results from database",
+		})
+		return
 	}
 
-	c.JSON(http.StatusOK, stats)
+	c.JSON(http.StatusOK, services.ComputeStats(entries))
 }
@@ -0,0 +1,746 @@
+package handlers
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/f1-analytics/models"
+	"github.com/f1-analytics/services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errLapNotFound is returned when OpenF1 has no timed lap to fetch telemetry for
+var errLapNotFound = errors.New("lap not found")
+
+// sessionDriverIDs stores the drivers OpenF1 lists for a session and maps
+// their car numbers to stored driver IDs, so that rows of drivers not yet in
+// the database are not dropped
+func sessionDriverIDs(db *gorm.DB, openF1Service OpenF1Service, sessionKey int, season int) (map[int]uint, error) {
+	apiDrivers, err := openF1Service.GetDrivers(nil, nil, &sessionKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	seasons, err := storeDrivers(db, apiDrivers, season)
+	if err != nil {
+		return nil, err
+	}
+
+	byNumber := make(map[int]uint, len(seasons))
+	for number, driverSeason := range seasons {
+		byNumber[number] = driverSeason.DriverID
+	}
+	return byNumber, nil
+}
+
+// ensureRaceKeys looks up a race's OpenF1 meeting and race session keys and
+// stores them when they are not stored yet. OpenF1 has no round numbers, so
+// the race is matched on its round among the season's races in date order.
+// Cancelled races and seasons OpenF1 does not cover are left without keys.
+func ensureRaceKeys(db *gorm.DB, openF1Service OpenF1Service, race *models.Race) error {
+	if race.MeetingKey != 0 && race.SessionKey != 0 {
+		return nil
+	}
+
+	// The keys may have been stored since the race was read
+	var stored models.Race
+	if err := db.Select("id", "meeting_key", "session_key").First(&stored, race.ID).Error; err != nil {
+		return err
+	}
+	race.MeetingKey, race.SessionKey = stored.MeetingKey, stored.SessionKey
+	if race.MeetingKey != 0 && race.SessionKey != 0 {
+		return nil
+	}
+	if race.Season < services.OpenF1FirstSeason || race.Round <= 0 || race.Status == "Cancelled" {
+		return nil
+	}
+
+	sessions, err := openF1Service.GetSeasonSessions(race.Season, models.SessionRace)
+	if err != nil {
+		return err
+	}
+	if race.Round > len(sessions) {
+		return nil
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].DateStart.Before(sessions[j].DateStart) })
+
+	session := sessions[race.Round-1]
+	race.MeetingKey, race.SessionKey = session.MeetingKey, session.SessionKey
+	return db.Model(&models.Race{}).Where("id = ?", race.ID).Updates(map[string]interface{}{
+		"meeting_key": race.MeetingKey,
+		"session_key": race.SessionKey,
+	}).Error
+}
+
+// secondsToDuration converts an OpenF1 duration in seconds
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// ensureRaceStints fetches a race's tyre stints from OpenF1 and stores them
+// when none are stored yet
+func ensureRaceStints(db *gorm.DB, openF1Service OpenF1Service, race models.Race) error {
+	var count int64
+	if err := db.Model(&models.Stint{}).Where("race_id = ?", race.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	if err := ensureRaceKeys(db, openF1Service, &race); err != nil || race.SessionKey == 0 {
+		return err
+	}
+
+	apiStints, err := openF1Service.GetStints(race.SessionKey)
+	if err != nil {
+		return err
+	}
+	driverIDs, err := sessionDriverIDs(db, openF1Service, race.SessionKey, race.Season)
+	if err != nil {
+		return err
+	}
+
+	var stints []models.Stint
+	for _, apiStint := range apiStints {
+		driverID, ok := driverIDs[apiStint.DriverNumber]
+		if !ok {
+			continue
+		}
+		stints = append(stints, models.Stint{
+			RaceID:         race.ID,
+			DriverID:       driverID,
+			StintNumber:    apiStint.StintNumber,
+			Compound:       apiStint.Compound,
+			LapStart:       apiStint.LapStart,
+			LapEnd:         apiStint.LapEnd,
+			TyreAgeAtStart: apiStint.TyreAgeAtStart,
+		})
+	}
+	if len(stints) == 0 {
+		return nil
+	}
+	return db.CreateInBatches(&stints, 100).Error
+}
+
+// ensureRaceLaps fetches a race's laps from OpenF1 and stores them when none
+// are stored yet
+func ensureRaceLaps(db *gorm.DB, openF1Service OpenF1Service, race models.Race) error {
+	return ensureSessionLaps(db, openF1Service, race, models.SessionRace)
+}
+
+// ensureSessionLaps fetches the laps of one session of a race weekend from
+// OpenF1 and stores them when none are stored yet. Race in-laps are marked
+// from the stored stints.
+func ensureSessionLaps(db *gorm.DB, openF1Service OpenF1Service, race models.Race, session string) error {
+	var count int64
+	if err := db.Model(&models.Lap{}).Where("race_id = ? AND session = ?", race.ID, session).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	apiSession, err := resolveSession(db, openF1Service, race, session)
+	if err != nil || apiSession.SessionKey == 0 {
+		return err
+	}
+	sessionKey := apiSession.SessionKey
+
+	// A driver pits at the end of the lap before each new stint starts
+	inLaps := make(map[uint]map[int]bool)
+	if session == models.SessionRace {
+		if err := ensureRaceStints(db, openF1Service, race); err != nil {
+			return err
+		}
+		var stints []models.Stint
+		if err := db.Where("race_id = ?", race.ID).Find(&stints).Error; err != nil {
+			return err
+		}
+		for _, stint := range stints {
+			if stint.StintNumber <= 1 || stint.LapStart <= 1 {
+				continue
+			}
+			if inLaps[stint.DriverID] == nil {
+				inLaps[stint.DriverID] = make(map[int]bool)
+			}
+			inLaps[stint.DriverID][stint.LapStart-1] = true
+		}
+	}
+
+	apiLaps, err := openF1Service.GetLaps(sessionKey)
+	if err != nil {
+		return err
+	}
+	driverIDs, err := sessionDriverIDs(db, openF1Service, sessionKey, race.Season)
+	if err != nil {
+		return err
+	}
+
+	var laps []models.Lap
+	for _, apiLap := range apiLaps {
+		driverID, ok := driverIDs[apiLap.DriverNumber]
+		if !ok {
+			continue
+		}
+		laps = append(laps, models.Lap{
+			RaceID:    race.ID,
+			DriverID:  driverID,
+			Session:   session,
+			LapNumber: apiLap.LapNumber,
+			DateStart: apiLap.DateStart,
+			LapTime:   secondsToDuration(apiLap.LapDuration),
+			Sector1:   secondsToDuration(apiLap.DurationSector1),
+			Sector2:   secondsToDuration(apiLap.DurationSector2),
+			Sector3:   secondsToDuration(apiLap.DurationSector3),
+			I1Speed:   apiLap.I1Speed,
+			I2Speed:   apiLap.I2Speed,
+			SpeedTrap: apiLap.StSpeed,
+			PitStop:   inLaps[driverID][apiLap.LapNumber],
+			PitOutLap: apiLap.IsPitOutLap,
+		})
+	}
+	if len(laps) == 0 {
+		return nil
+	}
+
+	if session == models.SessionRace {
+		if err := ensureRacePositions(db, openF1Service, race); err != nil {
+			return err
+		}
+		var samples []models.PositionSample
+		if err := db.Where("race_id = ?", race.ID).Find(&samples).Error; err != nil {
+			return err
+		}
+		positions := services.LapEndPositions(samples, laps)
+		for i := range laps {
+			laps[i].Position = positions[laps[i].DriverID][laps[i].LapNumber]
+		}
+
+		if err := ensureRaceControl(db, openF1Service, race); err != nil {
+			return err
+		}
+		periods, err := loadNeutralisedPeriods(db, race, laps)
+		if err != nil {
+			return err
+		}
+		services.FlagNeutralisedLaps(laps, periods)
+	}
+	return db.CreateInBatches(&laps, 500).Error
+}
+
+// ensureRacePositions fetches a race's running positions from OpenF1 and
+// stores them when none are stored yet. Laps already stored are given their
+// end-of-lap position.
+func ensureRacePositions(db *gorm.DB, openF1Service OpenF1Service, race models.Race) error {
+	var count int64
+	if err := db.Model(&models.PositionSample{}).Where("race_id = ?", race.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	if err := ensureRaceKeys(db, openF1Service, &race); err != nil || race.SessionKey == 0 {
+		return err
+	}
+
+	apiPositions, err := openF1Service.GetPositions(race.SessionKey)
+	if err != nil {
+		return err
+	}
+	driverIDs, err := sessionDriverIDs(db, openF1Service, race.SessionKey, race.Season)
+	if err != nil {
+		return err
+	}
+
+	var samples []models.PositionSample
+	for _, apiPosition := range apiPositions {
+		driverID, ok := driverIDs[apiPosition.DriverNumber]
+		if !ok || apiPosition.Position == 0 {
+			continue
+		}
+		samples = append(samples, models.PositionSample{
+			RaceID:   race.ID,
+			DriverID: driverID,
+			Date:     apiPosition.Date,
+			Position: apiPosition.Position,
+		})
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(&samples, 500).Error; err != nil {
+			return err
+		}
+
+		laps, err := loadRaceLaps(tx, race.ID)
+		if err != nil {
+			return err
+		}
+		positions := services.LapEndPositions(samples, laps)
+		for _, lap := range laps {
+			position := positions[lap.DriverID][lap.LapNumber]
+			if position == 0 || position == lap.Position {
+				continue
+			}
+			if err := tx.Model(&models.Lap{}).Where("id = ?", lap.ID).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ensureRaceIntervals fetches a race's gaps to the leader and the car ahead
+// from OpenF1 and stores them when none are stored yet. Samples with no known
+// gap to the leader are dropped.
+func ensureRaceIntervals(db *gorm.DB, openF1Service OpenF1Service, race models.Race) error {
+	var count int64
+	if err := db.Model(&models.IntervalSample{}).Where("race_id = ?", race.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	if err := ensureRaceKeys(db, openF1Service, &race); err != nil || race.SessionKey == 0 {
+		return err
+	}
+
+	apiIntervals, err := openF1Service.GetIntervals(race.SessionKey)
+	if err != nil {
+		return err
+	}
+	driverIDs, err := sessionDriverIDs(db, openF1Service, race.SessionKey, race.Season)
+	if err != nil {
+		return err
+	}
+
+	var samples []models.IntervalSample
+	for _, apiInterval := range apiIntervals {
+		driverID, ok := driverIDs[apiInterval.DriverNumber]
+		if !ok || !apiInterval.GapToLeader.Valid {
+			continue
+		}
+		samples = append(samples, models.IntervalSample{
+			RaceID:           race.ID,
+			DriverID:         driverID,
+			Date:             apiInterval.Date,
+			GapToLeader:      apiInterval.GapToLeader.Seconds,
+			LapsBehindLeader: apiInterval.GapToLeader.Laps,
+			Interval:         apiInterval.Interval.Seconds,
+			LapsBehindAhead:  apiInterval.Interval.Laps,
+		})
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+	return db.CreateInBatches(&samples, 1000).Error
+}
+
+// ensureRaceControl fetches a race's race control messages from OpenF1 and
+// stores them when none are stored yet. Laps already stored are flagged for
+// the safety car periods the messages describe.
+func ensureRaceControl(db *gorm.DB, openF1Service OpenF1Service, race models.Race) error {
+	var count int64
+	if err := db.Model(&models.RaceControlMessage{}).Where("race_id = ?", race.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	if err := ensureRaceKeys(db, openF1Service, &race); err != nil || race.SessionKey == 0 {
+		return err
+	}
+
+	apiMessages, err := openF1Service.GetRaceControl(race.SessionKey)
+	if err != nil {
+		return err
+	}
+
+	var messages []models.RaceControlMessage
+	for _, apiMessage := range apiMessages {
+		messages = append(messages, models.RaceControlMessage{
+			RaceID:    race.ID,
+			Date:      apiMessage.Date,
+			LapNumber: apiMessage.LapNumber,
+			Category:  apiMessage.Category,
+			Flag:      apiMessage.Flag,
+			Scope:     apiMessage.Scope,
+			Message:   apiMessage.Message,
+		})
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(&messages, 500).Error; err != nil {
+			return err
+		}
+
+		laps, err := loadRaceLaps(tx, race.ID)
+		if err != nil || len(laps) == 0 {
+			return err
+		}
+		periods := services.NeutralisedPeriods(messages, raceDistanceLaps(race, laps))
+		services.FlagNeutralisedLaps(laps, periods)
+		for _, lap := range laps {
+			if !lap.SafetyCar && !lap.VirtualSafetyCar {
+				continue
+			}
+			if err := tx.Model(&models.Lap{}).Where("id = ?", lap.ID).Updates(map[string]interface{}{
+				"safety_car":         lap.SafetyCar,
+				"virtual_safety_car": lap.VirtualSafetyCar,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// loadNeutralisedPeriods reads a race's safety car, virtual safety car and red
+// flag periods from its stored race control messages
+func loadNeutralisedPeriods(db *gorm.DB, race models.Race, laps []models.Lap) ([]services.NeutralisedPeriod, error) {
+	var messages []models.RaceControlMessage
+	if err := db.Where("race_id = ?", race.ID).Order("date").Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return services.NeutralisedPeriods(messages, raceDistanceLaps(race, laps)), nil
+}
+
+// ensureLapCarData fetches the car telemetry of a driver's laps from OpenF1
+// and stores it for each lap with none stored yet. The laps' starts and
+// durations come from OpenF1's laps of the session, fetched once for all of
+// them, so any session can be fetched by its session key.
+func ensureLapCarData(db *gorm.DB, openF1Service OpenF1Service, sessionKey int, driver models.Driver, lapNumbers ...int) error {
+	var stored []int
+	if err := db.Model(&models.CarDataSample{}).
+		Where("session_key = ? AND driver_id = ? AND lap_number IN ?", sessionKey, driver.ID, lapNumbers).
+		Distinct().Pluck("lap_number", &stored).Error; err != nil {
+		return err
+	}
+	have := make(map[int]bool, len(stored))
+	for _, lapNumber := range stored {
+		have[lapNumber] = true
+	}
+	var missing []int
+	for _, lapNumber := range lapNumbers {
+		if !have[lapNumber] {
+			missing = append(missing, lapNumber)
+			have[lapNumber] = true
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	apiLaps, err := openF1Service.GetLaps(sessionKey)
+	if err != nil {
+		return err
+	}
+	driverLaps := make(map[int]services.Lap)
+	for _, apiLap := range apiLaps {
+		if apiLap.DriverNumber == driver.Number {
+			driverLaps[apiLap.LapNumber] = apiLap
+		}
+	}
+
+	for _, lapNumber := range missing {
+		lap, ok := driverLaps[lapNumber]
+		if !ok || lap.DateStart.IsZero() || lap.LapDuration <= 0 {
+			return errLapNotFound
+		}
+
+		from := lap.DateStart
+		to := from.Add(secondsToDuration(lap.LapDuration))
+		apiSamples, err := openF1Service.GetCarData(sessionKey, driver.Number, from, to)
+		if err != nil {
+			return err
+		}
+		if len(apiSamples) == 0 {
+			return errLapNotFound
+		}
+
+		samples := make([]models.CarDataSample, len(apiSamples))
+		for i, apiSample := range apiSamples {
+			samples[i] = models.CarDataSample{
+				SessionKey: sessionKey,
+				DriverID:   driver.ID,
+				LapNumber:  lapNumber,
+				Date:       apiSample.Date,
+				Speed:      apiSample.Speed,
+				RPM:        apiSample.RPM,
+				Gear:       apiSample.Gear,
+				Throttle:   apiSample.Throttle,
+				Brake:      apiSample.Brake,
+				DRS:        apiSample.DRS,
+			}
+		}
+		if err := db.CreateInBatches(&samples, 1000).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureRaceResults fetches a race's classification from OpenF1 and stores it
+// when none is stored yet. Each result records the team the driver raced for,
+// and the race's constructor results are rebuilt once they are all stored.
+func ensureRaceResults(db *gorm.DB, openF1Service OpenF1Service, race models.Race) error {
+	var count int64
+	if err := db.Model(&models.RaceDriver{}).Where("race_id = ?", race.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	if err := ensureRaceKeys(db, openF1Service, &race); err != nil || race.SessionKey == 0 {
+		return err
+	}
+
+	apiResults, err := openF1Service.GetSessionResults(race.SessionKey)
+	if err != nil || len(apiResults) == 0 {
+		return err
+	}
+	apiDrivers, err := openF1Service.GetDrivers(nil, nil, &race.SessionKey, nil)
+	if err != nil {
+		return err
+	}
+	driverSeasons, err := storeDrivers(db, apiDrivers, race.Season)
+	if err != nil {
+		return err
+	}
+	grid, err := raceGrid(db, openF1Service, race)
+	if err != nil {
+		return err
+	}
+	raceSession, err := resolveSession(db, openF1Service, race, models.SessionRace)
+	if err != nil {
+		return err
+	}
+	scheduledLaps := services.ScheduledLaps(raceSession.CircuitShortName, race.Season)
+
+	// Each driver's fastest lap comes from the race's laps
+	if err := ensureRaceLaps(db, openF1Service, race); err != nil {
+		return err
+	}
+	var fastestLaps []struct {
+		DriverID uint
+		LapTime  time.Duration
+	}
+	if err := db.Model(&models.Lap{}).
+		Select("driver_id, MIN(lap_time) AS lap_time").
+		Where("race_id = ? AND session = ? AND lap_time > 0", race.ID, models.SessionRace).
+		Group("driver_id").
+		Scan(&fastestLaps).Error; err != nil {
+		return err
+	}
+	fastest := make(map[uint]time.Duration, len(fastestLaps))
+	for _, lap := range fastestLaps {
+		fastest[lap.DriverID] = lap.LapTime
+	}
+
+	var results []models.RaceDriver
+	lapsCompleted := 0
+	for _, apiResult := range apiResults {
+		if apiResult.Position == 1 {
+			lapsCompleted = apiResult.NumberOfLaps
+		}
+		driverSeason, ok := driverSeasons[apiResult.DriverNumber]
+		if !ok {
+			continue
+		}
+		results = append(results, models.RaceDriver{
+			DriverID:   driverSeason.DriverID,
+			RaceID:     race.ID,
+			TeamID:     driverSeason.TeamID,
+			Position:   apiResult.Position,
+			Points:     apiResult.Points,
+			Grid:       grid[apiResult.DriverNumber],
+			FastestLap: fastest[driverSeason.DriverID],
+			RaceTime:   secondsToDuration(apiResult.Duration.At(0)),
+			Status:     apiResult.Status(),
+		})
+	}
+	if len(results) == 0 {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&results).Error; err != nil {
+			return err
+		}
+		// The winner's laps against the scheduled laps tell a shortened race
+		// from a full one when scoring it
+		lapCounts := make(map[string]interface{})
+		if lapsCompleted > 0 {
+			lapCounts["laps_completed"] = lapsCompleted
+		}
+		if scheduledLaps > 0 && race.Laps == 0 {
+			lapCounts["laps"] = scheduledLaps
+		}
+		if len(lapCounts) > 0 {
+			if err := tx.Model(&models.Race{}).Where("id = ?", race.ID).Updates(lapCounts).Error; err != nil {
+				return err
+			}
+		}
+		return models.SyncRaceTeams(tx, race.ID)
+	})
+}
+
+// ensureSprintResults fetches the classification of a race weekend's sprint
+// from OpenF1 and stores it on the race results when none is stored yet. The
+// race's results must be stored first; weekends without a sprint are left as
+// they are.
+func ensureSprintResults(db *gorm.DB, openF1Service OpenF1Service, race models.Race) error {
+	var results, sprints int64
+	if err := db.Model(&models.RaceDriver{}).Where("race_id = ?", race.ID).Count(&results).Error; err != nil {
+		return err
+	}
+	if err := db.Model(&models.RaceDriver{}).Where("race_id = ? AND sprint_status <> ''", race.ID).Count(&sprints).Error; err != nil {
+		return err
+	}
+	if results == 0 || sprints > 0 {
+		return nil
+	}
+
+	sprint, err := resolveSession(db, openF1Service, race, models.SessionSprint)
+	if err != nil || sprint.SessionKey == 0 {
+		return err
+	}
+	apiResults, err := openF1Service.GetSessionResults(sprint.SessionKey)
+	if err != nil || len(apiResults) == 0 {
+		return err
+	}
+	driverIDs, err := sessionDriverIDs(db, openF1Service, sprint.SessionKey, race.Season)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, apiResult := range apiResults {
+			driverID, ok := driverIDs[apiResult.DriverNumber]
+			if !ok {
+				continue
+			}
+			if err := tx.Model(&models.RaceDriver{}).Where("race_id = ? AND driver_id = ?", race.ID, driverID).Updates(map[string]interface{}{
+				"sprint_position": apiResult.Position,
+				"sprint_points":   apiResult.Points,
+				"sprint_status":   apiResult.Status(),
+			}).Error; err != nil {
+				return err
+			}
+		}
+		// Mark the weekend as a sprint weekend for the championship calculators
+		if race.SprintTime.IsZero() && !sprint.DateStart.IsZero() {
+			if err := tx.Model(&models.Race{}).Where("id = ?", race.ID).Update("sprint_time", sprint.DateStart).Error; err != nil {
+				return err
+			}
+		}
+		return models.SyncRaceTeams(tx, race.ID)
+	})
+}
+
+// ensureQualifyingResults fetches a race weekend's qualifying classification
+// from OpenF1 and stores it when none is stored yet, with the team each
+// driver qualified for
+func ensureQualifyingResults(db *gorm.DB, openF1Service OpenF1Service, race models.Race) error {
+	var count int64
+	if err := db.Model(&models.QualifyingResult{}).Where("race_id = ?", race.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	qualifying, err := resolveSession(db, openF1Service, race, models.SessionQualifying)
+	if err != nil || qualifying.SessionKey == 0 {
+		return err
+	}
+	sessionKey := qualifying.SessionKey
+	apiResults, err := openF1Service.GetSessionResults(sessionKey)
+	if err != nil || len(apiResults) == 0 {
+		return err
+	}
+	apiDrivers, err := openF1Service.GetDrivers(nil, nil, &sessionKey, nil)
+	if err != nil {
+		return err
+	}
+	driverSeasons, err := storeDrivers(db, apiDrivers, race.Season)
+	if err != nil {
+		return err
+	}
+
+	var results []models.QualifyingResult
+	for _, apiResult := range apiResults {
+		driverSeason, ok := driverSeasons[apiResult.DriverNumber]
+		if !ok {
+			continue
+		}
+		results = append(results, models.QualifyingResult{
+			DriverID: driverSeason.DriverID,
+			RaceID:   race.ID,
+			TeamID:   driverSeason.TeamID,
+			Position: apiResult.Position,
+			Q1:       secondsToDuration(apiResult.Duration.At(0)),
+			Q2:       secondsToDuration(apiResult.Duration.At(1)),
+			Q3:       secondsToDuration(apiResult.Duration.At(2)),
+		})
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&results).Error
+}
+
+// raceGrid returns each car number's starting slot. OpenF1 publishes the grid
+// against the race or, for some weekends, against qualifying.
+func raceGrid(db *gorm.DB, openF1Service OpenF1Service, race models.Race) (map[int]int, error) {
+	apiGrid, err := openF1Service.GetStartingGrid(race.SessionKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(apiGrid) == 0 {
+		qualifying, err := resolveSession(db, openF1Service, race, models.SessionQualifying)
+		if err != nil {
+			return nil, err
+		}
+		if qualifying.SessionKey != 0 {
+			if apiGrid, err = openF1Service.GetStartingGrid(qualifying.SessionKey); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	grid := make(map[int]int, len(apiGrid))
+	for _, slot := range apiGrid {
+		grid[slot.DriverNumber] = slot.Position
+	}
+	return grid, nil
+}
+
+// resolveSession finds the OpenF1 session of a session of the race weekend,
+// looking up the race's keys first. It returns a zero session when the race
+// has no OpenF1 keys or the weekend had no such session.
+func resolveSession(db *gorm.DB, openF1Service OpenF1Service, race models.Race, session string) (services.Session, error) {
+	if err := ensureRaceKeys(db, openF1Service, &race); err != nil {
+		return services.Session{}, err
+	}
+	if race.MeetingKey == 0 {
+		return services.Session{}, nil
+	}
+
+	sessions, err := openF1Service.GetSessions(race.MeetingKey)
+	if err != nil {
+		return services.Session{}, err
+	}
+	for _, s := range sessions {
+		if s.SessionName == session {
+			return s, nil
+		}
+	}
+	return services.Session{}, nil
+}
@@ -1,6 +1,10 @@
 package handlers
 
-import "github.com/f1-analytics/services"
+import (
+	"time"
+
+	"github.com/f1-analytics/services"
+)
 
 // OpenF1Service defines the interface for interacting with the OpenF1 API
 type OpenF1Service interface {
@@ -9,4 +13,14 @@ type OpenF1Service interface {
 	GetRaces() ([]services.Race, error)
 	GetRaceResults(raceID string) ([]services.RaceResult, error)
 	GetCurrentSession() (*services.Session, error)
+	GetSessions(meetingKey int) ([]services.Session, error)
+	GetSeasonSessions(year int, sessionName string) ([]services.Session, error)
+	GetSessionResults(sessionKey int) ([]services.SessionResult, error)
+	GetStartingGrid(sessionKey int) ([]services.GridPosition, error)
+	GetLaps(sessionKey int) ([]services.Lap, error)
+	GetStints(sessionKey int) ([]services.Stint, error)
+	GetPositions(sessionKey int) ([]services.Position, error)
+	GetIntervals(sessionKey int) ([]services.Interval, error)
+	GetRaceControl(sessionKey int) ([]services.RaceControl, error)
+	GetCarData(sessionKey int, driverNumber int, from, to time.Time) ([]services.CarData, error)
 }
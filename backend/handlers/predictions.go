@@ -0,0 +1,373 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/f1-analytics/models"
+	"github.com/f1-analytics/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PredictionHandler struct {
+	openF1Service OpenF1Service
+	db            *gorm.DB
+}
+
+func NewPredictionHandler(openF1Service OpenF1Service, db *gorm.DB) *PredictionHandler {
+	return &PredictionHandler{
+		openF1Service: openF1Service,
+		db:            db,
+	}
+}
+
+// GetRacePrediction returns each driver's chance of every finishing position in
+// a race. The prediction is stored the first time it is made and kept,
+// ?refresh=true makes it again. Once the race has run the prediction is scored
+// against the result.
+func (h *PredictionHandler) GetRacePrediction(c *gin.Context) {
+	raceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid race ID",
+		})
+		return
+	}
+
+	var race models.Race
+	if err := h.db.First(&race, raceID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Race not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch race from database",
+		})
+		return
+	}
+
+	// If no qualifying in database, fetch from OpenF1 API and store it for the grid
+	if err := ensureQualifyingResults(h.db, h.openF1Service, race); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch qualifying results from API",
+		})
+		return
+	}
+
+	prediction, err := ensurePrediction(h.db, race, c.Query("refresh") == "true", nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to make prediction",
+		})
+		return
+	}
+	if len(prediction.Drivers) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "No entrants known for this race",
+		})
+		return
+	}
+
+	drivers, err := loadSeasonDrivers(h.db, race.Season)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch drivers from database",
+		})
+		return
+	}
+
+	type DriverPredictionResponse struct {
+		services.DriverPrediction
+		SeasonDriver
+	}
+
+	response := make([]DriverPredictionResponse, len(prediction.Drivers))
+	for i, driver := range prediction.Drivers {
+		response[i] = DriverPredictionResponse{DriverPrediction: driver, SeasonDriver: drivers[driver.DriverID]}
+	}
+
+	body := gin.H{
+		"race_id": race.ID,
+		"name":    race.Name,
+		"model":   prediction.Model,
+		"drivers": response,
+	}
+
+	var results []models.RaceDriver
+	if err := h.db.Where("race_id = ?", race.ID).Find(&results).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch race results",
+		})
+		return
+	}
+	if len(results) > 0 {
+		body["score"] = services.ScorePrediction(prediction, results)
+	}
+	c.JSON(http.StatusOK, body)
+}
+
+// GetBacktest scores the predictions for every race of a season that has
+// results, making any that are missing from what was known before each race
+func (h *PredictionHandler) GetBacktest(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid season",
+		})
+		return
+	}
+
+	season, err := loadSeasonResults(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch season results from database",
+		})
+		return
+	}
+	if len(season.Races) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Season not found",
+		})
+		return
+	}
+
+	type RaceScore struct {
+		services.PredictionScore
+		Round int    `json:"round"`
+		Name  string `json:"name"`
+	}
+
+	// Every prediction made here shares the seasons loaded for its history
+	seasons := map[int]services.SeasonResults{year: season}
+	races := []RaceScore{}
+	var scores []services.PredictionScore
+	for _, race := range season.Races {
+		results := season.Results[race.ID]
+		if len(results) == 0 {
+			continue
+		}
+		prediction, err := ensurePrediction(h.db, race, false, seasons)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to make prediction",
+			})
+			return
+		}
+		score := services.ScorePrediction(prediction, results)
+		if score.Drivers == 0 {
+			continue
+		}
+		scores = append(scores, score)
+		races = append(races, RaceScore{PredictionScore: score, Round: race.Round, Name: race.Name})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"season":  year,
+		"model":   services.PredictionModel,
+		"overall": services.CombineScores(scores),
+		"races":   races,
+	})
+}
+
+// ensurePrediction returns the stored prediction for a race from the current
+// model, making and storing one when there is none or refresh is set. seasons
+// caches season results between calls and may be nil.
+func ensurePrediction(db *gorm.DB, race models.Race, refresh bool, seasons map[int]services.SeasonResults) (services.RacePrediction, error) {
+	if !refresh {
+		var stored []models.RacePrediction
+		if err := db.Where("race_id = ? AND model = ?", race.ID, services.PredictionModel).Find(&stored).Error; err != nil {
+			return services.RacePrediction{}, err
+		}
+		if len(stored) > 0 {
+			var positions []models.PredictedPosition
+			if err := db.Where("race_id = ?", race.ID).Order("position").Find(&positions).Error; err != nil {
+				return services.RacePrediction{}, err
+			}
+			return storedPrediction(race.ID, stored, positions), nil
+		}
+	}
+
+	// If no prediction in database, make one from what was known before the race and store it
+	data, err := loadPredictionData(db, race, seasons)
+	if err != nil {
+		return services.RacePrediction{}, err
+	}
+	prediction := services.PredictRace(data)
+	if len(prediction.Drivers) == 0 {
+		return prediction, nil
+	}
+
+	var rows []models.RacePrediction
+	var positions []models.PredictedPosition
+	for _, driver := range prediction.Drivers {
+		rows = append(rows, models.RacePrediction{
+			DriverID:          driver.DriverID,
+			RaceID:            race.ID,
+			TeamID:            driver.TeamID,
+			Model:             prediction.Model,
+			Grid:              driver.Grid,
+			GridFactor:        driver.Factors.Grid,
+			FormFactor:        driver.Factors.Form,
+			CircuitFactor:     driver.Factors.Circuit,
+			TeamPaceFactor:    driver.Factors.TeamPace,
+			Score:             driver.Score,
+			DNFProbability:    driver.DNFProbability,
+			ExpectedPosition:  driver.ExpectedPosition,
+			WinProbability:    driver.WinProbability,
+			PodiumProbability: driver.PodiumProbability,
+			PointsProbability: driver.PointsProbability,
+		})
+		for i, p := range driver.Positions {
+			positions = append(positions, models.PredictedPosition{
+				DriverID:    driver.DriverID,
+				RaceID:      race.ID,
+				Position:    i + 1,
+				Probability: p,
+			})
+		}
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("race_id = ?", race.ID).Delete(&models.RacePrediction{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("race_id = ?", race.ID).Delete(&models.PredictedPosition{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&rows).Error; err != nil {
+			return err
+		}
+		return tx.CreateInBatches(&positions, 500).Error
+	})
+	return prediction, err
+}
+
+// storedPrediction converts a stored prediction back for the prediction service
+func storedPrediction(raceID uint, rows []models.RacePrediction, positions []models.PredictedPosition) services.RacePrediction {
+	byDriver := make(map[uint][]float64, len(rows))
+	for _, p := range positions {
+		byDriver[p.DriverID] = append(byDriver[p.DriverID], p.Probability)
+	}
+
+	prediction := services.RacePrediction{RaceID: raceID, Model: services.PredictionModel}
+	for _, row := range rows {
+		prediction.Drivers = append(prediction.Drivers, services.DriverPrediction{
+			DriverID: row.DriverID,
+			TeamID:   row.TeamID,
+			Grid:     row.Grid,
+			Factors: services.PredictionFactors{
+				Grid:     row.GridFactor,
+				Form:     row.FormFactor,
+				Circuit:  row.CircuitFactor,
+				TeamPace: row.TeamPaceFactor,
+			},
+			Score:             row.Score,
+			DNFProbability:    row.DNFProbability,
+			ExpectedPosition:  row.ExpectedPosition,
+			WinProbability:    row.WinProbability,
+			PodiumProbability: row.PodiumProbability,
+			PointsProbability: row.PointsProbability,
+			Positions:         byDriver[row.DriverID],
+		})
+	}
+	sort.SliceStable(prediction.Drivers, func(i, j int) bool {
+		return prediction.Drivers[i].ExpectedPosition < prediction.Drivers[j].ExpectedPosition
+	})
+	return prediction
+}
+
+// loadPredictionData gathers what was known before a race: its entrants and
+// grid, the races before it and the earlier visits to its circuit. Entrants
+// come from the result once the race has run, then from qualifying, then from
+// the previous race without a grid. Seasons loaded are added to seasons, which
+// may be nil.
+func loadPredictionData(db *gorm.DB, race models.Race, seasons map[int]services.SeasonResults) (services.PredictionData, error) {
+	data := services.PredictionData{
+		Race:       race,
+		Results:    make(map[uint][]models.RaceDriver),
+		Qualifying: make(map[uint][]models.QualifyingResult),
+	}
+
+	if err := db.Where("date < ?", race.Date).Order("date DESC").Limit(services.PredictionHistoryRaces).Find(&data.Recent).Error; err != nil {
+		return data, err
+	}
+	if err := db.Where("circuit_id = ? AND date < ?", race.CircuitID, race.Date).Order("date DESC").Limit(services.PredictionCircuitVisits).Find(&data.CircuitHistory).Error; err != nil {
+		return data, err
+	}
+
+	// Results come through the season so older ones pick up their team
+	if seasons == nil {
+		seasons = make(map[int]services.SeasonResults)
+	}
+	resultsOf := func(r models.Race) ([]models.RaceDriver, error) {
+		season, ok := seasons[r.Season]
+		if !ok {
+			var err error
+			if season, err = loadSeasonResults(db, r.Season); err != nil {
+				return nil, err
+			}
+			seasons[r.Season] = season
+		}
+		return season.Results[r.ID], nil
+	}
+	for _, races := range [][]models.Race{data.Recent, data.CircuitHistory} {
+		for _, r := range races {
+			results, err := resultsOf(r)
+			if err != nil {
+				return data, err
+			}
+			data.Results[r.ID] = results
+		}
+	}
+
+	recentIDs := make([]uint, len(data.Recent))
+	for i, r := range data.Recent {
+		recentIDs[i] = r.ID
+	}
+	if len(recentIDs) > 0 {
+		var qualifying []models.QualifyingResult
+		if err := db.Where("race_id IN ?", recentIDs).Find(&qualifying).Error; err != nil {
+			return data, err
+		}
+		for _, q := range qualifying {
+			data.Qualifying[q.RaceID] = append(data.Qualifying[q.RaceID], q)
+		}
+	}
+
+	var qualifying []models.QualifyingResult
+	if err := db.Where("race_id = ?", race.ID).Order("position").Find(&qualifying).Error; err != nil {
+		return data, err
+	}
+	qualifyingPositions := make(map[uint]int, len(qualifying))
+	for _, q := range qualifying {
+		qualifyingPositions[q.DriverID] = q.Position
+	}
+
+	results, err := resultsOf(race)
+	if err != nil {
+		return data, err
+	}
+	switch {
+	case len(results) > 0:
+		for _, result := range results {
+			entrant := models.RaceDriver{DriverID: result.DriverID, RaceID: race.ID, TeamID: result.TeamID, Grid: result.Grid}
+			if entrant.Grid <= 0 {
+				entrant.Grid = qualifyingPositions[result.DriverID]
+			}
+			data.Entrants = append(data.Entrants, entrant)
+		}
+	case len(qualifying) > 0:
+		for _, q := range qualifying {
+			data.Entrants = append(data.Entrants, models.RaceDriver{DriverID: q.DriverID, RaceID: race.ID, TeamID: q.TeamID, Grid: q.Position})
+		}
+	case len(data.Recent) > 0:
+		for _, result := range data.Results[data.Recent[0].ID] {
+			data.Entrants = append(data.Entrants, models.RaceDriver{DriverID: result.DriverID, RaceID: race.ID, TeamID: result.TeamID})
+		}
+	}
+	return data, nil
+}
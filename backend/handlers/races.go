@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -121,6 +122,26 @@ func (h *RaceHandler) GetRaceResults(c *gin.Context) {
 		return
 	}
 
+	// If no results in database, fetch from OpenF1 API and store them
+	if err := ensureRaceResults(h.db, h.openF1Service, race); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch race results from API",
+		})
+		return
+	}
+	if err := ensureSprintResults(h.db, h.openF1Service, race); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch sprint results from API",
+		})
+		return
+	}
+	if err := ensureQualifyingResults(h.db, h.openF1Service, race); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch qualifying results from API",
+		})
+		return
+	}
+
 	// Get race results from the join table
 	var results []models.RaceDriver
 	if err := h.db.Where("race_id = ?", raceID).Find(&results).Error; err != nil {
@@ -132,3 +153,824 @@ func (h *RaceHandler) GetRaceResults(c *gin.Context) {
 
 	c.JSON(http.StatusOK, results)
 }
+
+// GetRacePace returns each driver's representative race pace, ranked by median
+// and by mean
+func (h *RaceHandler) GetRacePace(c *gin.Context) {
+	raceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid race ID",
+		})
+		return
+	}
+
+	var race models.Race
+	result := h.db.First(&race, raceID)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Race not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch race from database",
+		})
+		return
+	}
+
+	opts, err := parsePaceOptions(c, false)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// If no laps in database, fetch from OpenF1 API and store them
+	if err := ensureRaceLaps(h.db, h.openF1Service, race); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch laps from API",
+		})
+		return
+	}
+
+	laps, err := loadRaceLaps(h.db, race.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch laps from database",
+		})
+		return
+	}
+
+	if opts.Fuel != nil {
+		opts.Fuel.TotalLaps = raceDistanceLaps(race, laps)
+	}
+
+	drivers, err := loadSeasonDrivers(h.db, race.Season)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch drivers from database",
+		})
+		return
+	}
+
+	type DriverPaceResponse struct {
+		services.DriverPace
+		SeasonDriver
+	}
+	withDrivers := func(pace []services.DriverPace) []DriverPaceResponse {
+		response := make([]DriverPaceResponse, len(pace))
+		for i, p := range pace {
+			response[i] = DriverPaceResponse{DriverPace: p, SeasonDriver: drivers[p.DriverID]}
+		}
+		return response
+	}
+
+	analysis := services.AnalysePace(laps, opts)
+	c.JSON(http.StatusOK, gin.H{
+		"race_id":         race.ID,
+		"fuel_correction": analysis.FuelCorrection,
+		"by_median":       withDrivers(analysis.ByMedian),
+		"by_mean":         withDrivers(analysis.ByMean),
+	})
+}
+
+// parsePaceOptions reads the lap cleaning and fuel correction settings from the
+// query string. fuelByDefault turns fuel correction on unless
+// fuel_correction=false.
+func parsePaceOptions(c *gin.Context, fuelByDefault bool) (services.PaceOptions, error) {
+	var opts services.PaceOptions
+
+	if thresholdStr := c.Query("outlier_threshold"); thresholdStr != "" {
+		threshold, err := strconv.ParseFloat(thresholdStr, 64)
+		if err != nil || threshold <= 0 {
+			return opts, fmt.Errorf("invalid outlier_threshold: %s", thresholdStr)
+		}
+		opts.OutlierThreshold = threshold
+	}
+
+	switch c.Query("fuel_correction") {
+	case "true":
+	case "false":
+		return opts, nil
+	default:
+		if !fuelByDefault {
+			return opts, nil
+		}
+	}
+
+	fuel := services.FuelCorrection{
+		KgPerLap:     services.DefaultFuelKgPerLap,
+		SecondsPerKg: services.DefaultSecondsPerKg,
+	}
+	if kgStr := c.Query("kg_per_lap"); kgStr != "" {
+		kg, err := strconv.ParseFloat(kgStr, 64)
+		if err != nil || kg < 0 {
+			return opts, fmt.Errorf("invalid kg_per_lap: %s", kgStr)
+		}
+		fuel.KgPerLap = kg
+	}
+	if secondsStr := c.Query("seconds_per_kg"); secondsStr != "" {
+		seconds, err := strconv.ParseFloat(secondsStr, 64)
+		if err != nil || seconds < 0 {
+			return opts, fmt.Errorf("invalid seconds_per_kg: %s", secondsStr)
+		}
+		fuel.SecondsPerKg = seconds
+	}
+	opts.Fuel = &fuel
+
+	return opts, nil
+}
+
+// GetRaceDegradation fits a tyre degradation model to every stint of a race
+func (h *RaceHandler) GetRaceDegradation(c *gin.Context) {
+	raceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid race ID",
+		})
+		return
+	}
+
+	var race models.Race
+	result := h.db.First(&race, raceID)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Race not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch race from database",
+		})
+		return
+	}
+
+	opts, err := parsePaceOptions(c, true)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	model := services.DegradationModel(c.DefaultQuery("model", string(services.DegradationAuto)))
+	if model != services.DegradationLinear && model != services.DegradationQuadratic && model != services.DegradationAuto {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid model, expected linear, quadratic or auto",
+		})
+		return
+	}
+
+	// If no stints or laps in database, fetch from OpenF1 API and store them
+	if err := ensureRaceLaps(h.db, h.openF1Service, race); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch laps from API",
+		})
+		return
+	}
+
+	laps, err := loadRaceLaps(h.db, race.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch laps from database",
+		})
+		return
+	}
+	var stints []models.Stint
+	if err := h.db.Where("race_id = ?", race.ID).Order("driver_id, stint_number").Find(&stints).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch stints from database",
+		})
+		return
+	}
+
+	if opts.Fuel != nil {
+		opts.Fuel.TotalLaps = raceDistanceLaps(race, laps)
+	}
+
+	drivers, err := loadSeasonDrivers(h.db, race.Season)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch drivers from database",
+		})
+		return
+	}
+
+	type StintResponse struct {
+		services.StintDegradation
+		SeasonDriver
+	}
+
+	analysis := services.AnalyseDegradation(laps, stints, opts.Fuel, model)
+	response := make([]StintResponse, len(analysis.Stints))
+	for i, stint := range analysis.Stints {
+		response[i] = StintResponse{StintDegradation: stint, SeasonDriver: drivers[stint.DriverID]}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"race_id":         race.ID,
+		"fuel_correction": analysis.FuelCorrection,
+		"stints":          response,
+		"compounds":       analysis.Compounds,
+	})
+}
+
+// GetRacePitExchanges returns every undercut and overcut attempt of a race
+func (h *RaceHandler) GetRacePitExchanges(c *gin.Context) {
+	raceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid race ID",
+		})
+		return
+	}
+
+	var race models.Race
+	result := h.db.First(&race, raceID)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Race not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch race from database",
+		})
+		return
+	}
+
+	// If no laps in database, fetch from OpenF1 API and store them
+	if err := ensureRaceLaps(h.db, h.openF1Service, race); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch laps from API",
+		})
+		return
+	}
+
+	laps, err := loadRaceLaps(h.db, race.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch laps from database",
+		})
+		return
+	}
+
+	drivers, err := loadSeasonDrivers(h.db, race.Season)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch drivers from database",
+		})
+		return
+	}
+
+	type PitExchangeResponse struct {
+		services.PitExchange
+		Attacker SeasonDriver `json:"attacker"`
+		Defender SeasonDriver `json:"defender"`
+	}
+
+	exchanges := services.DetectPitExchanges(race.ID, laps)
+	response := make([]PitExchangeResponse, len(exchanges))
+	for i, exchange := range exchanges {
+		response[i] = PitExchangeResponse{
+			PitExchange: exchange,
+			Attacker:    drivers[exchange.AttackerID],
+			Defender:    drivers[exchange.DefenderID],
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"race_id":   race.ID,
+		"exchanges": response,
+	})
+}
+
+// GetRaceSectors returns each driver's best sectors and theoretical best lap
+// for a session of the weekend
+func (h *RaceHandler) GetRaceSectors(c *gin.Context) {
+	raceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid race ID",
+		})
+		return
+	}
+
+	session, ok := parseSession(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid session",
+		})
+		return
+	}
+
+	var race models.Race
+	result := h.db.First(&race, raceID)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Race not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch race from database",
+		})
+		return
+	}
+
+	// If no laps for the session in database, fetch from OpenF1 API and store them
+	if err := ensureSessionLaps(h.db, h.openF1Service, race, session); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch laps from API",
+		})
+		return
+	}
+
+	laps, err := loadSessionLaps(h.db, race.ID, session)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch laps from database",
+		})
+		return
+	}
+
+	drivers, err := loadSeasonDrivers(h.db, race.Season)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch drivers from database",
+		})
+		return
+	}
+
+	type DriverSectorsResponse struct {
+		services.DriverSectors
+		SeasonDriver
+	}
+
+	analysis := services.AnalyseSectors(session, laps)
+	response := make([]DriverSectorsResponse, len(analysis.Drivers))
+	for i, driver := range analysis.Drivers {
+		response[i] = DriverSectorsResponse{DriverSectors: driver, SeasonDriver: drivers[driver.DriverID]}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"race_id":        race.ID,
+		"session":        analysis.Session,
+		"purple_sectors": analysis.PurpleSectors,
+		"ideal_lap":      analysis.IdealLap,
+		"drivers":        response,
+	})
+}
+
+// GetRaceSpeeds summarises intermediate and speed trap speeds per driver and
+// team for a session of the weekend
+func (h *RaceHandler) GetRaceSpeeds(c *gin.Context) {
+	raceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid race ID",
+		})
+		return
+	}
+
+	session, ok := parseSession(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid session",
+		})
+		return
+	}
+
+	var race models.Race
+	result := h.db.First(&race, raceID)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Race not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch race from database",
+		})
+		return
+	}
+
+	// If no laps for the session in database, fetch from OpenF1 API and store them
+	if err := ensureSessionLaps(h.db, h.openF1Service, race, session); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch laps from API",
+		})
+		return
+	}
+
+	laps, err := loadSessionLaps(h.db, race.ID, session)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch laps from database",
+		})
+		return
+	}
+
+	driverTeams, err := loadDriverTeams(h.db, race.Season)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch drivers from database",
+		})
+		return
+	}
+	var results []models.RaceDriver
+	if err := h.db.Where("race_id = ?", race.ID).Find(&results).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch race results",
+		})
+		return
+	}
+
+	drivers, err := loadSeasonDrivers(h.db, race.Season)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch drivers from database",
+		})
+		return
+	}
+	teams, err := loadSeasonTeams(h.db, race.Season)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch teams from database",
+		})
+		return
+	}
+
+	type DriverSpeedsResponse struct {
+		services.DriverSpeeds
+		SeasonDriver
+	}
+	type TeamSpeedsResponse struct {
+		services.TeamSpeeds
+		SeasonTeam
+	}
+
+	driverSpeeds, teamSpeeds := services.SummariseSpeeds(laps, raceTeamOf(driverTeams, results))
+	driverResponse := make([]DriverSpeedsResponse, len(driverSpeeds))
+	for i, speeds := range driverSpeeds {
+		driverResponse[i] = DriverSpeedsResponse{DriverSpeeds: speeds, SeasonDriver: drivers[speeds.DriverID]}
+	}
+	teamResponse := make([]TeamSpeedsResponse, len(teamSpeeds))
+	for i, speeds := range teamSpeeds {
+		teamResponse[i] = TeamSpeedsResponse{TeamSpeeds: speeds, SeasonTeam: teams[speeds.TeamID]}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"race_id": race.ID,
+		"session": session,
+		"drivers": driverResponse,
+		"teams":   teamResponse,
+	})
+}
+
+// GetRaceOvertakes lists every change in the running order of a race,
+// separating on-track passes from those caused by pit stops, retirements and
+// penalties
+func (h *RaceHandler) GetRaceOvertakes(c *gin.Context) {
+	raceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid race ID",
+		})
+		return
+	}
+
+	var race models.Race
+	result := h.db.First(&race, raceID)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Race not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch race from database",
+		})
+		return
+	}
+
+	// If no laps or positions in database, fetch from OpenF1 API and store them
+	if err := ensureRaceLaps(h.db, h.openF1Service, race); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch laps from API",
+		})
+		return
+	}
+	if err := ensureRacePositions(h.db, h.openF1Service, race); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch positions from API",
+		})
+		return
+	}
+
+	overtakes, err := loadRaceOvertakes(h.db, race)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch positions from database",
+		})
+		return
+	}
+
+	drivers, err := loadSeasonDrivers(h.db, race.Season)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch drivers from database",
+		})
+		return
+	}
+
+	type PositionChangeResponse struct {
+		services.PositionChange
+		Driver SeasonDriver `json:"driver"`
+		Passed SeasonDriver `json:"passed"`
+	}
+
+	onTrack := []PositionChangeResponse{}
+	other := []PositionChangeResponse{}
+	byCause := make(map[string]int)
+	for _, change := range overtakes.Changes {
+		entry := PositionChangeResponse{
+			PositionChange: change,
+			Driver:         drivers[change.DriverID],
+			Passed:         drivers[change.PassedID],
+		}
+		if change.Cause == services.ChangeOvertake {
+			onTrack = append(onTrack, entry)
+		} else {
+			other = append(other, entry)
+		}
+		byCause[change.Cause]++
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"race_id":          race.ID,
+		"overtakes":        onTrack,
+		"position_changes": other,
+		"by_cause":         byCause,
+	})
+}
+
+// GetRaceGaps returns every driver's gap to the leader and interval to the car
+// ahead over a race, per lap and as the raw timeline
+func (h *RaceHandler) GetRaceGaps(c *gin.Context) {
+	raceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid race ID",
+		})
+		return
+	}
+
+	includeTimeline, err := strconv.ParseBool(c.DefaultQuery("timeline", "true"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid timeline flag",
+		})
+		return
+	}
+
+	var race models.Race
+	result := h.db.First(&race, raceID)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Race not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch race from database",
+		})
+		return
+	}
+
+	// If no laps or intervals in database, fetch from OpenF1 API and store them
+	if err := ensureRaceLaps(h.db, h.openF1Service, race); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch laps from API",
+		})
+		return
+	}
+	if err := ensureRaceIntervals(h.db, h.openF1Service, race); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch intervals from API",
+		})
+		return
+	}
+
+	laps, err := loadRaceLaps(h.db, race.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch laps from database",
+		})
+		return
+	}
+	var samples []models.IntervalSample
+	if err := h.db.Where("race_id = ?", race.ID).Order("date").Find(&samples).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch intervals from database",
+		})
+		return
+	}
+
+	drivers, err := loadSeasonDrivers(h.db, race.Season)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch drivers from database",
+		})
+		return
+	}
+
+	type DriverGapsResponse struct {
+		services.DriverGaps
+		SeasonDriver
+	}
+
+	series := services.GapSeries(samples, laps, includeTimeline)
+	response := make([]DriverGapsResponse, len(series))
+	for i, gaps := range series {
+		response[i] = DriverGapsResponse{DriverGaps: gaps, SeasonDriver: drivers[gaps.DriverID]}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"race_id": race.ID,
+		"drivers": response,
+	})
+}
+
+// GetRaceLapChart returns the running order at the end of every lap, with pit
+// stops, retirements and safety car periods
+func (h *RaceHandler) GetRaceLapChart(c *gin.Context) {
+	raceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid race ID",
+		})
+		return
+	}
+
+	var race models.Race
+	result := h.db.First(&race, raceID)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Race not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch race from database",
+		})
+		return
+	}
+
+	// If no laps or race control messages in database, fetch from OpenF1 API and store them
+	if err := ensureRaceLaps(h.db, h.openF1Service, race); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch laps from API",
+		})
+		return
+	}
+	if err := ensureRaceControl(h.db, h.openF1Service, race); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch race control messages from API",
+		})
+		return
+	}
+
+	laps, err := loadRaceLaps(h.db, race.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch laps from database",
+		})
+		return
+	}
+	periods, err := loadNeutralisedPeriods(h.db, race, laps)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch race control messages from database",
+		})
+		return
+	}
+	var results []models.RaceDriver
+	if err := h.db.Where("race_id = ?", race.ID).Find(&results).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch race results from database",
+		})
+		return
+	}
+
+	drivers, err := loadSeasonDrivers(h.db, race.Season)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch drivers from database",
+		})
+		return
+	}
+
+	type LapChartDriverResponse struct {
+		services.LapChartDriver
+		SeasonDriver
+	}
+
+	chart := services.BuildLapChart(laps, results, periods)
+	response := make([]LapChartDriverResponse, len(chart.Drivers))
+	for i, line := range chart.Drivers {
+		response[i] = LapChartDriverResponse{LapChartDriver: line, SeasonDriver: drivers[line.DriverID]}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"race_id":     race.ID,
+		"laps":        chart.Laps,
+		"drivers":     response,
+		"neutralised": chart.Neutralised,
+	})
+}
+
+// parseSession reads ?session= and checks it names a session of a race weekend,
+// defaulting to the race
+func parseSession(c *gin.Context) (string, bool) {
+	session := c.DefaultQuery("session", models.SessionRace)
+	switch session {
+	case models.SessionPractice1, models.SessionPractice2, models.SessionPractice3,
+		models.SessionQualifying, models.SessionSprintQualifying, models.SessionSprint, models.SessionRace:
+		return session, true
+	}
+	return "", false
+}
+
+// loadRaceLaps returns every stored lap of a race in lap order
+func loadRaceLaps(db *gorm.DB, raceID uint) ([]models.Lap, error) {
+	return loadSessionLaps(db, raceID, models.SessionRace)
+}
+
+// loadSessionLaps returns every stored lap of one session of a race weekend in
+// lap order
+func loadSessionLaps(db *gorm.DB, raceID uint, session string) ([]models.Lap, error) {
+	var laps []models.Lap
+	err := db.Where("race_id = ? AND session = ?", raceID, session).Order("driver_id, lap_number").Find(&laps).Error
+	return laps, err
+}
+
+// loadRaceOvertakes detects the running-order changes of a race from its stored
+// positions and laps
+func loadRaceOvertakes(db *gorm.DB, race models.Race) (services.RaceOvertakes, error) {
+	var samples []models.PositionSample
+	if err := db.Where("race_id = ?", race.ID).Find(&samples).Error; err != nil {
+		return services.RaceOvertakes{}, err
+	}
+	laps, err := loadRaceLaps(db, race.ID)
+	if err != nil {
+		return services.RaceOvertakes{}, err
+	}
+
+	overtakes := services.RaceOvertakes{
+		RaceID:    race.ID,
+		CircuitID: race.CircuitID,
+		Changes:   services.DetectPositionChanges(race.ID, samples, laps),
+	}
+	if len(samples) == 0 {
+		return overtakes, nil
+	}
+
+	overtakes.Laps = raceDistanceLaps(race, laps)
+	if race.LapsCompleted > 0 {
+		overtakes.Laps = race.LapsCompleted
+	}
+	seen := make(map[uint]bool)
+	for _, sample := range samples {
+		if !seen[sample.DriverID] {
+			seen[sample.DriverID] = true
+			overtakes.Drivers = append(overtakes.Drivers, sample.DriverID)
+		}
+	}
+	return overtakes, nil
+}
+
+// raceDistanceLaps returns the scheduled race length, falling back to the
+// longest stored lap count
+func raceDistanceLaps(race models.Race, laps []models.Lap) int {
+	if race.Laps > 0 {
+		return race.Laps
+	}
+	total := 0
+	for _, lap := range laps {
+		if lap.LapNumber > total {
+			total = lap.LapNumber
+		}
+	}
+	return total
+}
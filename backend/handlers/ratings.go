@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/f1-analytics/models"
+	"github.com/f1-analytics/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RatingHandler struct {
+	openF1Service OpenF1Service
+	db            *gorm.DB
+}
+
+func NewRatingHandler(openF1Service OpenF1Service, db *gorm.DB) *RatingHandler {
+	return &RatingHandler{
+		openF1Service: openF1Service,
+		db:            db,
+	}
+}
+
+// GetDriverRatings ranks every driver by rating as of a date, today by default
+func (h *RatingHandler) GetDriverRatings(c *gin.Context) {
+	asOf := time.Now()
+	if asOfStr := c.Query("as_of"); asOfStr != "" {
+		date, err := time.Parse("2006-01-02", asOfStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid as_of date, expected YYYY-MM-DD",
+			})
+			return
+		}
+		asOf = date.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	if err := ensureRatings(h.db); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to compute ratings",
+		})
+		return
+	}
+
+	var ratings []models.DriverRating
+	if err := h.db.Where("date <= ?", asOf).Order("date, season, round").Find(&ratings).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch ratings from database",
+		})
+		return
+	}
+
+	table := services.RatingTable(ratingChanges(ratings))
+
+	// Drivers are shown as they were in the season of their latest rating
+	drivers := make(map[int]map[uint]SeasonDriver)
+	for _, entry := range table {
+		if _, ok := drivers[entry.Season]; ok {
+			continue
+		}
+		seasonDrivers, err := loadSeasonDrivers(h.db, entry.Season)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch drivers from database",
+			})
+			return
+		}
+		drivers[entry.Season] = seasonDrivers
+	}
+
+	type DriverRatingResponse struct {
+		Rank int `json:"rank"`
+		services.RatingChange
+		SeasonDriver
+	}
+
+	response := make([]DriverRatingResponse, len(table))
+	for i, entry := range table {
+		response[i] = DriverRatingResponse{
+			Rank:         i + 1,
+			RatingChange: entry,
+			SeasonDriver: drivers[entry.Season][entry.DriverID],
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"as_of":   asOf.Format("2006-01-02"),
+		"drivers": response,
+	})
+}
+
+// GetDriverRatingHistory returns a driver's rating after every race they were
+// rated in
+func (h *RatingHandler) GetDriverRatingHistory(c *gin.Context) {
+	number, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid driver number",
+		})
+		return
+	}
+
+	var driver models.Driver
+	if err := h.db.First(&driver, "number = ?", number).Error; err != nil {
+		respondDriverLookupError(c, err)
+		return
+	}
+
+	if err := ensureRatings(h.db); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to compute ratings",
+		})
+		return
+	}
+
+	var ratings []models.DriverRating
+	if err := h.db.Where("driver_id = ?", driver.ID).Order("date, season, round").Find(&ratings).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch ratings from database",
+		})
+		return
+	}
+
+	history := ratingChanges(ratings)
+	peak := services.RatingChange{}
+	for _, entry := range history {
+		if entry.Rating > peak.Rating {
+			peak = entry
+		}
+	}
+
+	response := gin.H{
+		"driver_number": driver.Number,
+		"name":          driver.Name,
+		"history":       history,
+	}
+	if len(history) > 0 {
+		response["current"] = history[len(history)-1].Rating
+		response["peak"] = peak
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ratingChanges converts stored ratings for the rating service
+func ratingChanges(ratings []models.DriverRating) []services.RatingChange {
+	changes := make([]services.RatingChange, len(ratings))
+	for i, r := range ratings {
+		changes[i] = services.RatingChange{
+			DriverID: r.DriverID,
+			RaceID:   r.RaceID,
+			Season:   r.Season,
+			Round:    r.Round,
+			Date:     r.Date,
+			Before:   r.Rating - r.Change,
+			Rating:   r.Rating,
+			Change:   r.Change,
+		}
+	}
+	return changes
+}
+
+// ratingsLockKey names the Postgres advisory lock held while ratings are rebuilt
+const ratingsLockKey = 4501
+
+// ensureRatings reruns the rating history from the first stored season
+// whenever any result has been stored, changed or deleted since the ratings
+// were last computed. Concurrent requests wait on a lock, and only the first
+// of them rebuilds the ratings.
+func ensureRatings(db *gorm.DB) error {
+	if stale, err := ratingsStale(db); err != nil || !stale {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", ratingsLockKey).Error; err != nil {
+			return err
+		}
+		// Another request may have rebuilt them while this one waited
+		if stale, err := ratingsStale(tx); err != nil || !stale {
+			return err
+		}
+		return rebuildRatings(tx)
+	})
+}
+
+// ratingsStale reports whether any result, deleted ones included, has changed
+// since the ratings were last computed
+func ratingsStale(db *gorm.DB) (bool, error) {
+	var latestResult struct {
+		UpdatedAt sql.NullTime
+		DeletedAt sql.NullTime
+	}
+	if err := db.Unscoped().Model(&models.RaceDriver{}).
+		Select("MAX(updated_at) AS updated_at, MAX(deleted_at) AS deleted_at").
+		Scan(&latestResult).Error; err != nil {
+		return false, err
+	}
+	if !latestResult.UpdatedAt.Valid {
+		return false, nil
+	}
+	changed := latestResult.UpdatedAt.Time
+	if latestResult.DeletedAt.Valid && latestResult.DeletedAt.Time.After(changed) {
+		changed = latestResult.DeletedAt.Time
+	}
+
+	var latestRating []models.DriverRating
+	if err := db.Select("created_at").Order("created_at DESC").Limit(1).Find(&latestRating).Error; err != nil {
+		return false, err
+	}
+	return len(latestRating) == 0 || latestRating[0].CreatedAt.Before(changed), nil
+}
+
+// rebuildRatings replaces the stored ratings with a fresh run over every season
+func rebuildRatings(db *gorm.DB) error {
+	var years []int
+	if err := db.Model(&models.Race{}).Distinct("season").Order("season").Pluck("season", &years).Error; err != nil {
+		return err
+	}
+	seasons := make([]services.SeasonResults, 0, len(years))
+	for _, year := range years {
+		season, err := loadSeasonResults(db, year)
+		if err != nil {
+			return err
+		}
+		seasons = append(seasons, season)
+	}
+
+	changes := services.RateDrivers(seasons)
+	ratings := make([]models.DriverRating, len(changes))
+	for i, change := range changes {
+		ratings[i] = models.DriverRating{
+			DriverID: change.DriverID,
+			RaceID:   change.RaceID,
+			Season:   change.Season,
+			Round:    change.Round,
+			Date:     change.Date,
+			Rating:   change.Rating,
+			Change:   change.Change,
+		}
+	}
+
+	if err := db.Where("1 = 1").Delete(&models.DriverRating{}).Error; err != nil {
+		return err
+	}
+	if len(ratings) == 0 {
+		return nil
+	}
+	return db.CreateInBatches(&ratings, 500).Error
+}
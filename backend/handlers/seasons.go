@@ -0,0 +1,603 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/f1-analytics/models"
+	"github.com/f1-analytics/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SeasonHandler struct {
+	openF1Service OpenF1Service
+	db            *gorm.DB
+}
+
+func NewSeasonHandler(openF1Service OpenF1Service, db *gorm.DB) *SeasonHandler {
+	return &SeasonHandler{
+		openF1Service: openF1Service,
+		db:            db,
+	}
+}
+
+// GetPitExchanges returns each team's undercut and overcut record over a season
+func (h *SeasonHandler) GetPitExchanges(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid season",
+		})
+		return
+	}
+
+	season, err := loadSeasonResults(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch season results from database",
+		})
+		return
+	}
+	if len(season.Races) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Season not found",
+		})
+		return
+	}
+
+	var exchanges []services.PitExchange
+	for _, race := range season.Races {
+		laps, err := loadRaceLaps(h.db, race.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch laps from database",
+			})
+			return
+		}
+		exchanges = append(exchanges, services.DetectPitExchanges(race.ID, laps)...)
+	}
+
+	teams, err := loadSeasonTeams(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch teams from database",
+		})
+		return
+	}
+
+	type TeamPitExchangesResponse struct {
+		services.TeamPitExchanges
+		SeasonTeam
+	}
+
+	aggregated := services.AggregatePitExchanges(exchanges, season.TeamOf)
+	response := make([]TeamPitExchangesResponse, len(aggregated))
+	for i, team := range aggregated {
+		response[i] = TeamPitExchangesResponse{
+			TeamPitExchanges: team,
+			SeasonTeam:       teams[team.TeamID],
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"season":    year,
+		"exchanges": len(exchanges),
+		"teams":     response,
+	})
+}
+
+// GetSeasonSpeeds compares each team's straight-line speed across the circuits
+// of a season
+func (h *SeasonHandler) GetSeasonSpeeds(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid season",
+		})
+		return
+	}
+
+	session, ok := parseSession(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid session",
+		})
+		return
+	}
+
+	season, err := loadSeasonResults(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch season results from database",
+		})
+		return
+	}
+	if len(season.Races) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Season not found",
+		})
+		return
+	}
+
+	teams, err := loadSeasonTeams(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch teams from database",
+		})
+		return
+	}
+
+	// Drivers without a result for a round still count for their season's team
+	driverTeams, err := loadDriverTeams(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch drivers from database",
+		})
+		return
+	}
+
+	type TeamRoundSpeed struct {
+		SeasonTeam
+		TeamID             uint    `json:"team_id"`
+		TrapMax            float64 `json:"trap_max"`
+		TrapMedian         float64 `json:"trap_median"`
+		TrapDeltaToFastest float64 `json:"trap_delta_to_fastest"`
+	}
+	type RoundSpeeds struct {
+		Round  int              `json:"round"`
+		RaceID uint             `json:"race_id"`
+		Race   string           `json:"race"`
+		Teams  []TeamRoundSpeed `json:"teams"`
+	}
+	type TeamSeasonSpeed struct {
+		SeasonTeam
+		TeamID                    uint    `json:"team_id"`
+		Rounds                    int     `json:"rounds"`
+		AverageTrapDeltaToFastest float64 `json:"average_trap_delta_to_fastest"`
+	}
+
+	var rounds []RoundSpeeds
+	deltaTotals := make(map[uint]float64)
+	deltaCounts := make(map[uint]int)
+	for _, race := range season.Races {
+		laps, err := loadSessionLaps(h.db, race.ID, session)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch laps from database",
+			})
+			return
+		}
+		if len(laps) == 0 {
+			continue
+		}
+
+		_, teamSpeeds := services.SummariseSpeeds(laps, raceTeamOf(driverTeams, season.Results[race.ID]))
+		round := RoundSpeeds{Round: race.Round, RaceID: race.ID, Race: race.Name}
+		for _, speeds := range teamSpeeds {
+			if speeds.SpeedTrap.Samples == 0 {
+				continue
+			}
+			round.Teams = append(round.Teams, TeamRoundSpeed{
+				SeasonTeam:         teams[speeds.TeamID],
+				TeamID:             speeds.TeamID,
+				TrapMax:            speeds.SpeedTrap.Max,
+				TrapMedian:         speeds.SpeedTrap.Median,
+				TrapDeltaToFastest: speeds.TrapDeltaToFastest,
+			})
+			deltaTotals[speeds.TeamID] += speeds.TrapDeltaToFastest
+			deltaCounts[speeds.TeamID]++
+		}
+		if len(round.Teams) > 0 {
+			rounds = append(rounds, round)
+		}
+	}
+
+	summary := make([]TeamSeasonSpeed, 0, len(deltaCounts))
+	for teamID, count := range deltaCounts {
+		summary = append(summary, TeamSeasonSpeed{
+			SeasonTeam:                teams[teamID],
+			TeamID:                    teamID,
+			Rounds:                    count,
+			AverageTrapDeltaToFastest: deltaTotals[teamID] / float64(count),
+		})
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		if summary[i].AverageTrapDeltaToFastest != summary[j].AverageTrapDeltaToFastest {
+			return summary[i].AverageTrapDeltaToFastest < summary[j].AverageTrapDeltaToFastest
+		}
+		return summary[i].TeamID < summary[j].TeamID
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"season":  year,
+		"session": session,
+		"rounds":  rounds,
+		"teams":   summary,
+	})
+}
+
+// GetSeasonOvertakes tallies every driver's on-track passes made and suffered
+// over a season
+func (h *SeasonHandler) GetSeasonOvertakes(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid season",
+		})
+		return
+	}
+
+	season, err := loadSeasonResults(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch season results from database",
+		})
+		return
+	}
+	if len(season.Races) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Season not found",
+		})
+		return
+	}
+
+	var races []services.RaceOvertakes
+	for _, race := range season.Races {
+		overtakes, err := loadRaceOvertakes(h.db, race)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch positions from database",
+			})
+			return
+		}
+		if len(overtakes.Drivers) > 0 {
+			races = append(races, overtakes)
+		}
+	}
+
+	drivers, err := loadSeasonDrivers(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch drivers from database",
+		})
+		return
+	}
+
+	type DriverOvertakesResponse struct {
+		services.DriverOvertakes
+		SeasonDriver
+	}
+
+	tallies := services.TallyOvertakes(races)
+	response := make([]DriverOvertakesResponse, len(tallies))
+	for i, tally := range tallies {
+		response[i] = DriverOvertakesResponse{
+			DriverOvertakes: tally,
+			SeasonDriver:    drivers[tally.DriverID],
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"season":  year,
+		"races":   len(races),
+		"drivers": response,
+	})
+}
+
+// GetTeamPerformance tracks every team's qualifying and race pace gap to the
+// quickest across a season
+func (h *SeasonHandler) GetTeamPerformance(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid season",
+		})
+		return
+	}
+
+	window := services.DefaultTrendWindow
+	if windowStr := c.Query("window"); windowStr != "" {
+		window, err = strconv.Atoi(windowStr)
+		if err != nil || window < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid window",
+			})
+			return
+		}
+	}
+
+	opts, err := parsePaceOptions(c, false)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	season, err := loadSeasonResults(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch season results from database",
+		})
+		return
+	}
+	if len(season.Races) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Season not found",
+		})
+		return
+	}
+
+	rounds := make([]services.PerformanceRound, 0, len(season.Races))
+	for _, race := range season.Races {
+		var qualifying []models.QualifyingResult
+		if err := h.db.Where("race_id = ?", race.ID).Find(&qualifying).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch qualifying results from database",
+			})
+			return
+		}
+		laps, err := loadRaceLaps(h.db, race.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch laps from database",
+			})
+			return
+		}
+		rounds = append(rounds, services.PerformanceRound{
+			Round:      race.Round,
+			RaceID:     race.ID,
+			TotalLaps:  raceDistanceLaps(race, laps),
+			Qualifying: qualifying,
+			Laps:       laps,
+			TeamOf: func(driverID uint) uint {
+				return season.TeamOf(race.ID, driverID)
+			},
+		})
+	}
+
+	teams, err := loadSeasonTeams(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch teams from database",
+		})
+		return
+	}
+
+	type TeamPerformanceResponse struct {
+		services.TeamPerformance
+		SeasonTeam
+	}
+
+	performance := services.TeamPerformanceTrend(rounds, opts, window)
+	response := make([]TeamPerformanceResponse, len(performance))
+	for i, team := range performance {
+		response[i] = TeamPerformanceResponse{TeamPerformance: team, SeasonTeam: teams[team.TeamID]}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"season": year,
+		"window": window,
+		"teams":  response,
+	})
+}
+
+// GetReliability classifies how every car's races ended over a season and
+// compares reliability by team and by power unit supplier
+func (h *SeasonHandler) GetReliability(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid season",
+		})
+		return
+	}
+
+	season, err := loadSeasonResults(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch season results from database",
+		})
+		return
+	}
+	if len(season.Races) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Season not found",
+		})
+		return
+	}
+
+	teams, err := loadSeasonTeams(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch teams from database",
+		})
+		return
+	}
+
+	// Suppliers change between seasons, so they come from the team's season.
+	// Seasons stored before suppliers were recorded fall back to the known deals.
+	var teamSeasons []models.TeamSeason
+	if err := h.db.Where("season = ?", year).Find(&teamSeasons).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch teams from database",
+		})
+		return
+	}
+	powerUnits := make(map[uint]string, len(teams))
+	for teamID, team := range teams {
+		powerUnits[teamID] = services.PowerUnitSupplier(team.Name, year)
+	}
+	for _, ts := range teamSeasons {
+		if ts.PowerUnit != "" {
+			powerUnits[ts.TeamID] = ts.PowerUnit
+		}
+	}
+
+	type TeamReliabilityResponse struct {
+		services.TeamReliability
+		SeasonTeam
+	}
+
+	reliability := services.SeasonReliabilityStats(season, powerUnits)
+	response := make([]TeamReliabilityResponse, len(reliability.Teams))
+	for i, team := range reliability.Teams {
+		response[i] = TeamReliabilityResponse{TeamReliability: team, SeasonTeam: teams[team.TeamID]}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"season":       year,
+		"causes_known": reliability.CausesKnown,
+		"overall":      reliability.Overall,
+		"teams":        response,
+		"power_units":  reliability.PowerUnits,
+		"statuses":     reliability.Statuses,
+	})
+}
+
+// GetSeasonStarts compares every driver's grid slot with their position after
+// the first lap over a season, broken down by side of the grid
+func (h *SeasonHandler) GetSeasonStarts(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid season",
+		})
+		return
+	}
+
+	season, err := loadSeasonResults(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch season results from database",
+		})
+		return
+	}
+	if len(season.Races) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Season not found",
+		})
+		return
+	}
+
+	var races []services.StartRace
+	for _, race := range season.Races {
+		results := season.Results[race.ID]
+		if len(results) == 0 {
+			continue
+		}
+		lapOne, err := loadLapOnePositions(h.db, race.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch positions from database",
+			})
+			return
+		}
+		lapsStarted, err := loadLapsStarted(h.db, race.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch laps from database",
+			})
+			return
+		}
+		races = append(races, services.StartRace{
+			RaceID:      race.ID,
+			Round:       race.Round,
+			Results:     results,
+			LapOne:      lapOne,
+			LapsStarted: lapsStarted,
+		})
+	}
+
+	drivers, err := loadSeasonDrivers(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch drivers from database",
+		})
+		return
+	}
+
+	type DriverStartsResponse struct {
+		services.DriverStarts
+		SeasonDriver
+	}
+
+	starts := services.AnalyseStarts(races)
+	response := make([]DriverStartsResponse, len(starts.Drivers))
+	for i, driver := range starts.Drivers {
+		response[i] = DriverStartsResponse{DriverStarts: driver, SeasonDriver: drivers[driver.DriverID]}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"season":    year,
+		"races":     starts.Races,
+		"overall":   starts.Overall,
+		"odd_side":  starts.OddSide,
+		"even_side": starts.EvenSide,
+		"slots":     starts.Slots,
+		"drivers":   response,
+	})
+}
+
+// loadLapOnePositions returns every driver's position at the end of the first
+// lap of a race, from the stored lap positions and, for drivers without one,
+// from the position samples
+func loadLapOnePositions(db *gorm.DB, raceID uint) (map[uint]int, error) {
+	var laps []models.Lap
+	err := db.Where("race_id = ? AND session = ? AND lap_number <= 2", raceID, models.SessionRace).Order("driver_id, lap_number").Find(&laps).Error
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make(map[uint]int)
+	missing := false
+	for _, lap := range laps {
+		if lap.LapNumber != 1 {
+			continue
+		}
+		if lap.Position > 0 {
+			positions[lap.DriverID] = lap.Position
+		} else {
+			missing = true
+		}
+	}
+	if !missing && len(positions) > 0 {
+		return positions, nil
+	}
+
+	var samples []models.PositionSample
+	if err := db.Where("race_id = ?", raceID).Find(&samples).Error; err != nil {
+		return nil, err
+	}
+	for driverID, lapPositions := range services.LapEndPositions(samples, laps) {
+		if _, ok := positions[driverID]; !ok && lapPositions[1] > 0 {
+			positions[driverID] = lapPositions[1]
+		}
+	}
+	return positions, nil
+}
+
+// loadLapsStarted returns the highest race lap each driver started, from the
+// stored laps
+func loadLapsStarted(db *gorm.DB, raceID uint) (map[uint]int, error) {
+	var rows []struct {
+		DriverID uint
+		Laps     int
+	}
+	err := db.Model(&models.Lap{}).
+		Select("driver_id, MAX(lap_number) AS laps").
+		Where("race_id = ? AND session = ?", raceID, models.SessionRace).
+		Group("driver_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	laps := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		laps[row.DriverID] = row.Laps
+	}
+	return laps, nil
+}
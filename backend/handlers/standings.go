@@ -0,0 +1,699 @@
+package handlers
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+
+	"github.com/f1-analytics/models"
+	"github.com/f1-analytics/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type StandingsHandler struct {
+	openF1Service OpenF1Service
+	db            *gorm.DB
+}
+
+func NewStandingsHandler(openF1Service OpenF1Service, db *gorm.DB) *StandingsHandler {
+	return &StandingsHandler{
+		openF1Service: openF1Service,
+		db:            db,
+	}
+}
+
+// GetDriverStandings returns the drivers' championship after a given round
+func (h *StandingsHandler) GetDriverStandings(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid season",
+		})
+		return
+	}
+
+	afterRound := 0
+	if roundStr := c.Query("after_round"); roundStr != "" {
+		afterRound, err = strconv.Atoi(roundStr)
+		if err != nil || afterRound < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid after_round",
+			})
+			return
+		}
+	}
+
+	season, err := loadSeasonResults(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch season results from database",
+		})
+		return
+	}
+	if len(season.Races) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Season not found",
+		})
+		return
+	}
+	if afterRound == 0 {
+		afterRound = season.LastRound()
+	}
+
+	standings := services.ComputeDriverStandings(season, afterRound)
+
+	drivers, err := loadSeasonDrivers(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch drivers from database",
+		})
+		return
+	}
+
+	type DriverStandingResponse struct {
+		services.DriverStanding
+		SeasonDriver
+	}
+
+	response := make([]DriverStandingResponse, len(standings))
+	for i, standing := range standings {
+		response[i] = DriverStandingResponse{
+			DriverStanding: standing,
+			SeasonDriver:   drivers[standing.DriverID],
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"season":        year,
+		"after_round":   afterRound,
+		"points_system": seasonPointsSystem(year),
+		"standings":     response,
+	})
+}
+
+// GetConstructorStandings returns the constructors' championship with its
+// round-by-round progression
+func (h *StandingsHandler) GetConstructorStandings(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid season",
+		})
+		return
+	}
+
+	afterRound := 0
+	if roundStr := c.Query("after_round"); roundStr != "" {
+		afterRound, err = strconv.Atoi(roundStr)
+		if err != nil || afterRound < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid after_round",
+			})
+			return
+		}
+	}
+
+	season, err := loadSeasonResults(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch season results from database",
+		})
+		return
+	}
+	if len(season.Races) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Season not found",
+		})
+		return
+	}
+	if afterRound == 0 {
+		afterRound = season.LastRound()
+	}
+
+	standings := services.ComputeConstructorStandings(season, afterRound)
+
+	teams, err := loadSeasonTeams(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch teams from database",
+		})
+		return
+	}
+
+	type ConstructorStandingResponse struct {
+		services.ConstructorStanding
+		SeasonTeam
+	}
+
+	response := make([]ConstructorStandingResponse, len(standings))
+	for i, standing := range standings {
+		response[i] = ConstructorStandingResponse{
+			ConstructorStanding: standing,
+			SeasonTeam:          teams[standing.TeamID],
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"season":        year,
+		"after_round":   afterRound,
+		"points_system": seasonPointsSystem(year),
+		"standings":     response,
+	})
+}
+
+// GetWhatIfStandings recomputes a season's championships under a different
+// points system
+func (h *StandingsHandler) GetWhatIfStandings(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid season",
+		})
+		return
+	}
+
+	system, err := services.LookupPointsSystem(c.Query("system"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Unknown points system",
+		})
+		return
+	}
+
+	season, err := loadSeasonResults(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch season results from database",
+		})
+		return
+	}
+	if len(season.Races) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Season not found",
+		})
+		return
+	}
+
+	rescored := services.RescoreSeason(season, system)
+	driverStandings := services.ComputeDriverStandings(rescored, 0)
+	constructorStandings := services.ComputeConstructorStandings(rescored, 0)
+
+	drivers, err := loadSeasonDrivers(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch drivers from database",
+		})
+		return
+	}
+	teams, err := loadSeasonTeams(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch teams from database",
+		})
+		return
+	}
+
+	// Report each entry's actual championship position alongside the what-if one
+	actualDrivers := make(map[uint]services.DriverStanding)
+	for _, standing := range services.ComputeDriverStandings(season, 0) {
+		actualDrivers[standing.DriverID] = standing
+	}
+	actualConstructors := make(map[uint]services.ConstructorStanding)
+	for _, standing := range services.ComputeConstructorStandings(season, 0) {
+		actualConstructors[standing.TeamID] = standing
+	}
+
+	type WhatIfDriver struct {
+		services.DriverStanding
+		SeasonDriver
+		ActualPosition int     `json:"actual_position"`
+		ActualPoints   float64 `json:"actual_points"`
+	}
+	type WhatIfConstructor struct {
+		services.ConstructorStanding
+		SeasonTeam
+		ActualPosition int     `json:"actual_position"`
+		ActualPoints   float64 `json:"actual_points"`
+	}
+
+	driverResponse := make([]WhatIfDriver, len(driverStandings))
+	for i, standing := range driverStandings {
+		actual := actualDrivers[standing.DriverID]
+		driverResponse[i] = WhatIfDriver{
+			DriverStanding: standing,
+			SeasonDriver:   drivers[standing.DriverID],
+			ActualPosition: actual.Position,
+			ActualPoints:   actual.Points,
+		}
+	}
+	constructorResponse := make([]WhatIfConstructor, len(constructorStandings))
+	for i, standing := range constructorStandings {
+		actual := actualConstructors[standing.TeamID]
+		constructorResponse[i] = WhatIfConstructor{
+			ConstructorStanding: standing,
+			SeasonTeam:          teams[standing.TeamID],
+			ActualPosition:      actual.Position,
+			ActualPoints:        actual.Points,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"season":        year,
+		"points_system": system.Key,
+		"actual_system": seasonPointsSystem(year),
+		"drivers":       driverResponse,
+		"constructors":  constructorResponse,
+	})
+}
+
+// seasonPointsSystem returns the key of the points system a season was scored
+// under, or nil for seasons the registry does not cover
+func seasonPointsSystem(year int) interface{} {
+	system, err := services.PointsSystemForSeason(year)
+	if err != nil {
+		return nil
+	}
+	return system.Key
+}
+
+// GetPointsSystems lists the registered points systems and the seasons they cover
+func (h *StandingsHandler) GetPointsSystems(c *gin.Context) {
+	c.JSON(http.StatusOK, services.PointsSystems)
+}
+
+// SeasonDriver is the presentation data of a driver for one season
+type SeasonDriver struct {
+	DriverNumber int    `json:"driver_number"`
+	Name         string `json:"name"`
+	NameAcronym  string `json:"name_acronym"`
+	Team         string `json:"team"`
+	TeamColor    string `json:"team_colour"`
+}
+
+// GetScenarios works out whether a driver (?driver=number) or constructor
+// (?team=ID) can still win the championship and what they need at the next
+// weekend to clinch it or stay in contention against each rival
+func (h *StandingsHandler) GetScenarios(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid season",
+		})
+		return
+	}
+
+	driverStr, teamStr := c.Query("driver"), c.Query("team")
+	if (driverStr == "") == (teamStr == "") {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Exactly one of driver or team is required",
+		})
+		return
+	}
+
+	season, err := loadSeasonResults(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch season results from database",
+		})
+		return
+	}
+	if len(season.Races) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Season not found",
+		})
+		return
+	}
+
+	system, err := services.PointsSystemForSeason(year)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	remaining := services.RemainingWeekends(season, system)
+
+	var subjectID uint
+	var table []services.ScenarioEntry
+	maxPoints := make([]float64, len(remaining))
+	if driverStr != "" {
+		number, err := strconv.Atoi(driverStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid driver number",
+			})
+			return
+		}
+		var driver models.Driver
+		if err := h.db.First(&driver, "number = ?", number).Error; err != nil {
+			respondDriverLookupError(c, err)
+			return
+		}
+		subjectID = driver.ID
+		for _, standing := range services.ComputeDriverStandings(season, 0) {
+			table = append(table, services.ScenarioEntry{ID: standing.DriverID, Points: standing.Points})
+		}
+		for i, weekend := range remaining {
+			maxPoints[i] = weekend.MaxDriverPoints
+		}
+	} else {
+		teamID, err := strconv.Atoi(teamStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid team ID",
+			})
+			return
+		}
+		subjectID = uint(teamID)
+		for _, standing := range services.ComputeConstructorStandings(season, 0) {
+			table = append(table, services.ScenarioEntry{ID: standing.TeamID, Points: standing.Points})
+		}
+		for i, weekend := range remaining {
+			maxPoints[i] = weekend.MaxTeamPoints
+		}
+	}
+
+	found := false
+	for _, entry := range table {
+		found = found || entry.ID == subjectID
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "No championship entry for this season",
+		})
+		return
+	}
+
+	scenario := services.ChampionshipScenarios(table, subjectID, maxPoints)
+	if len(remaining) > 0 {
+		scenario.NextRound = &remaining[0]
+		// Finishing positions only make sense for a driver on a Grand Prix-only weekend
+		if driverStr != "" && !remaining[0].Sprint {
+			for i := range scenario.Rivals {
+				services.RaceFinishRequirements(&scenario.Rivals[i], system)
+			}
+		}
+	}
+
+	type RivalResponse struct {
+		services.RivalScenario
+		Driver *SeasonDriver `json:"driver,omitempty"`
+		Team   *SeasonTeam   `json:"team,omitempty"`
+	}
+
+	drivers, err := loadSeasonDrivers(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch drivers from database",
+		})
+		return
+	}
+	teams, err := loadSeasonTeams(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch teams from database",
+		})
+		return
+	}
+
+	rivals := make([]RivalResponse, len(scenario.Rivals))
+	for i, rival := range scenario.Rivals {
+		rivals[i] = RivalResponse{RivalScenario: rival}
+		if driverStr != "" {
+			driver := drivers[rival.RivalID]
+			rivals[i].Driver = &driver
+		} else {
+			team := teams[rival.RivalID]
+			rivals[i].Team = &team
+		}
+	}
+
+	type ScenarioResponse struct {
+		services.ChampionshipScenario
+		Rivals []RivalResponse `json:"rivals"`
+	}
+
+	response := gin.H{
+		"season":        year,
+		"points_system": system.Key,
+		"remaining":     remaining,
+		"scenario":      ScenarioResponse{ChampionshipScenario: scenario, Rivals: rivals},
+	}
+	if driverStr != "" {
+		response["driver"] = drivers[subjectID]
+	} else {
+		response["team"] = teams[subjectID]
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetProjection simulates the rest of a season (?runs=, ?seed=, ?form_window=)
+// and returns each driver's and constructor's chance of every final
+// championship position. Without a seed one is picked and returned.
+func (h *StandingsHandler) GetProjection(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid season",
+		})
+		return
+	}
+
+	opts := services.ProjectionOptions{
+		Runs:       services.DefaultProjectionRuns,
+		Seed:       rand.Uint64N(services.MaxProjectionSeed + 1),
+		FormWindow: services.DefaultFormWindow,
+	}
+	if runsStr := c.Query("runs"); runsStr != "" {
+		runs, err := strconv.Atoi(runsStr)
+		if err != nil || runs <= 0 || runs > services.MaxProjectionRuns {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid number of runs",
+			})
+			return
+		}
+		opts.Runs = runs
+	}
+	if seedStr := c.Query("seed"); seedStr != "" {
+		seed, err := strconv.ParseUint(seedStr, 10, 64)
+		if err != nil || seed > services.MaxProjectionSeed {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid seed",
+			})
+			return
+		}
+		opts.Seed = seed
+	}
+	if windowStr := c.Query("form_window"); windowStr != "" {
+		window, err := strconv.Atoi(windowStr)
+		if err != nil || window <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid form window",
+			})
+			return
+		}
+		opts.FormWindow = window
+	}
+
+	season, err := loadSeasonResults(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch season results from database",
+		})
+		return
+	}
+	if len(season.Races) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Season not found",
+		})
+		return
+	}
+
+	system, err := services.PointsSystemForSeason(year)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	remaining := services.RemainingWeekends(season, system)
+	projection := services.ProjectSeason(season, system, remaining, opts)
+
+	drivers, err := loadSeasonDrivers(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch drivers from database",
+		})
+		return
+	}
+	teams, err := loadSeasonTeams(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch teams from database",
+		})
+		return
+	}
+
+	type DriverProjection struct {
+		services.ProjectedEntry
+		Driver SeasonDriver `json:"driver"`
+	}
+	type TeamProjection struct {
+		services.ProjectedEntry
+		Team SeasonTeam `json:"team"`
+	}
+
+	driverProjections := make([]DriverProjection, len(projection.Drivers))
+	for i, entry := range projection.Drivers {
+		driverProjections[i] = DriverProjection{ProjectedEntry: entry, Driver: drivers[entry.ID]}
+	}
+	teamProjections := make([]TeamProjection, len(projection.Constructors))
+	for i, entry := range projection.Constructors {
+		teamProjections[i] = TeamProjection{ProjectedEntry: entry, Team: teams[entry.ID]}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"season":           year,
+		"points_system":    system.Key,
+		"options":          projection.Options,
+		"remaining_rounds": projection.RemainingRounds,
+		"form":             projection.Form,
+		"drivers":          driverProjections,
+		"constructors":     teamProjections,
+	})
+}
+
+// loadSeasonResults fetches a season's races in round order along with their results
+func loadSeasonResults(db *gorm.DB, year int) (services.SeasonResults, error) {
+	season := services.SeasonResults{
+		Season:  year,
+		Results: make(map[uint][]models.RaceDriver),
+	}
+
+	if err := db.Where("season = ?", year).Order("round").Find(&season.Races).Error; err != nil {
+		return season, err
+	}
+	if len(season.Races) == 0 {
+		return season, nil
+	}
+
+	raceIDs := make([]uint, len(season.Races))
+	for i, race := range season.Races {
+		raceIDs[i] = race.ID
+	}
+
+	var results []models.RaceDriver
+	if err := db.Where("race_id IN ?", raceIDs).Find(&results).Error; err != nil {
+		return season, err
+	}
+
+	// Results stored before teams were tracked per race fall back to the
+	// driver's team for the season. A driver's current team says nothing of
+	// where they raced in an earlier season, so it is never used.
+	seasonTeams, err := loadDriverTeams(db, year)
+	if err != nil {
+		return season, err
+	}
+
+	for _, result := range results {
+		if result.TeamID == 0 {
+			result.TeamID = seasonTeams[result.DriverID]
+		}
+		season.Results[result.RaceID] = append(season.Results[result.RaceID], result)
+	}
+
+	return season, nil
+}
+
+// loadDriverTeams returns each driver's team for a season from their season
+// entries, keyed by driver ID
+func loadDriverTeams(db *gorm.DB, year int) (map[uint]uint, error) {
+	var driverSeasons []models.DriverSeason
+	if err := db.Where("season = ?", year).Find(&driverSeasons).Error; err != nil {
+		return nil, err
+	}
+	teams := make(map[uint]uint, len(driverSeasons))
+	for _, ds := range driverSeasons {
+		teams[ds.DriverID] = ds.TeamID
+	}
+	return teams, nil
+}
+
+// raceTeamOf resolves the team a driver counts for at a race: the team on
+// their result where the race has one, or their team for the season
+func raceTeamOf(driverTeams map[uint]uint, results []models.RaceDriver) func(driverID uint) uint {
+	teams := make(map[uint]uint, len(driverTeams))
+	for driverID, teamID := range driverTeams {
+		teams[driverID] = teamID
+	}
+	for _, result := range results {
+		if result.TeamID != 0 {
+			teams[result.DriverID] = result.TeamID
+		}
+	}
+	return func(driverID uint) uint {
+		return teams[driverID]
+	}
+}
+
+// loadSeasonDrivers returns every driver keyed by ID, using the season's
+// acronym, team and colour where they were recorded
+func loadSeasonDrivers(db *gorm.DB, year int) (map[uint]SeasonDriver, error) {
+	var drivers []models.Driver
+	if err := db.Preload("Team").Preload("Seasons", "season = ?", year).Preload("Seasons.Team").Find(&drivers).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]SeasonDriver, len(drivers))
+	for _, driver := range drivers {
+		seasonDriver := SeasonDriver{
+			DriverNumber: driver.Number,
+			Name:         driver.Name,
+			NameAcronym:  driver.NameAcronym,
+			Team:         driver.Team.Name,
+			TeamColor:    driver.Team.Color,
+		}
+		for _, ds := range driver.Seasons {
+			if ds.NameAcronym != "" {
+				seasonDriver.NameAcronym = ds.NameAcronym
+			}
+			if ds.Team.Name != "" {
+				seasonDriver.Team = ds.Team.Name
+			}
+			seasonDriver.TeamColor = ds.TeamColor
+		}
+		byID[driver.ID] = seasonDriver
+	}
+
+	return byID, nil
+}
+
+// SeasonTeam is the presentation data of a team for one season
+type SeasonTeam struct {
+	Name      string `json:"name"`
+	TeamColor string `json:"team_colour"`
+}
+
+// loadSeasonTeams returns every team keyed by ID, using the season's colour
+// where it was recorded
+func loadSeasonTeams(db *gorm.DB, year int) (map[uint]SeasonTeam, error) {
+	var teams []models.Team
+	if err := db.Preload("Seasons", "season = ?", year).Find(&teams).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]SeasonTeam, len(teams))
+	for _, team := range teams {
+		seasonTeam := SeasonTeam{
+			Name:      team.Name,
+			TeamColor: team.Color,
+		}
+		for _, ts := range team.Seasons {
+			seasonTeam.TeamColor = ts.Color
+		}
+		byID[team.ID] = seasonTeam
+	}
+
+	return byID, nil
+}
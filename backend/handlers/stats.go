@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/f1-analytics/models"
+	"github.com/f1-analytics/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// seasonRange limits a query to a span of seasons, zero meaning unbounded
+type seasonRange struct {
+	from int
+	to   int
+}
+
+// parseSeasonRange reads ?season= or ?from=&to= from the query string
+func parseSeasonRange(c *gin.Context) (seasonRange, error) {
+	var r seasonRange
+	if seasonStr := c.Query("season"); seasonStr != "" {
+		season, err := strconv.Atoi(seasonStr)
+		if err != nil {
+			return r, fmt.Errorf("invalid season: %s", seasonStr)
+		}
+		return seasonRange{from: season, to: season}, nil
+	}
+	if fromStr := c.Query("from"); fromStr != "" {
+		from, err := strconv.Atoi(fromStr)
+		if err != nil {
+			return r, fmt.Errorf("invalid from: %s", fromStr)
+		}
+		r.from = from
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		to, err := strconv.Atoi(toStr)
+		if err != nil {
+			return r, fmt.Errorf("invalid to: %s", toStr)
+		}
+		r.to = to
+	}
+	if r.from != 0 && r.to != 0 && r.from > r.to {
+		return r, fmt.Errorf("from is after to")
+	}
+	return r, nil
+}
+
+// loadSeasonRange loads the results of every season within the range, oldest first
+func loadSeasonRange(db *gorm.DB, r seasonRange) ([]services.SeasonResults, error) {
+	query := db.Model(&models.Race{})
+	if r.from != 0 {
+		query = query.Where("season >= ?", r.from)
+	}
+	if r.to != 0 {
+		query = query.Where("season <= ?", r.to)
+	}
+
+	var years []int
+	if err := query.Distinct().Order("season").Pluck("season", &years).Error; err != nil {
+		return nil, err
+	}
+
+	seasons := make([]services.SeasonResults, 0, len(years))
+	for _, year := range years {
+		season, err := loadSeasonResults(db, year)
+		if err != nil {
+			return nil, err
+		}
+		seasons = append(seasons, season)
+	}
+	return seasons, nil
+}
+
+// statEntries flattens the results accepted by match into statistics entries,
+// marking poles from qualifying and the fastest lap of each race
+func statEntries(db *gorm.DB, seasons []services.SeasonResults, match func(models.RaceDriver) bool) ([]services.StatEntry, error) {
+	var raceIDs []uint
+	for _, season := range seasons {
+		for _, race := range season.Races {
+			raceIDs = append(raceIDs, race.ID)
+		}
+	}
+	if len(raceIDs) == 0 {
+		return nil, nil
+	}
+
+	var poles []models.QualifyingResult
+	if err := db.Where("race_id IN ? AND position = 1", raceIDs).Find(&poles).Error; err != nil {
+		return nil, err
+	}
+	poleSitters := make(map[uint]uint, len(poles))
+	for _, pole := range poles {
+		poleSitters[pole.RaceID] = pole.DriverID
+	}
+
+	var circuits []models.Circuit
+	if err := db.Find(&circuits).Error; err != nil {
+		return nil, err
+	}
+	circuitNames := make(map[uint]string, len(circuits))
+	for _, circuit := range circuits {
+		circuitNames[circuit.ID] = circuit.Name
+	}
+
+	var entries []services.StatEntry
+	for _, season := range seasons {
+		for _, race := range season.Races {
+			results := season.Results[race.ID]
+
+			fastestDriver := services.FastestLapDriver(results)
+
+			// Without a stored qualifying classification, fall back to the grid
+			poleSitter, hasQualifying := poleSitters[race.ID]
+
+			for _, result := range results {
+				if !match(result) {
+					continue
+				}
+				pole := result.Grid == 1
+				if hasQualifying {
+					pole = poleSitter == result.DriverID
+				}
+				entries = append(entries, services.StatEntry{
+					Season:     race.Season,
+					Round:      race.Round,
+					CircuitID:  race.CircuitID,
+					Circuit:    circuitNames[race.CircuitID],
+					Position:   result.Position,
+					Points:     result.Points + result.SprintPoints,
+					Status:     result.Status,
+					Pole:       pole,
+					FastestLap: result.DriverID == fastestDriver,
+				})
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// seasonComplete reports whether every race of a season has been run
+func seasonComplete(season services.SeasonResults) bool {
+	if len(season.Races) == 0 {
+		return false
+	}
+	for _, race := range season.Races {
+		if race.Status != "Completed" && race.Status != "Cancelled" {
+			return false
+		}
+	}
+	return true
+}
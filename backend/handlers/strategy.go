@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/f1-analytics/models"
+	"github.com/f1-analytics/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type StrategyHandler struct {
+	openF1Service OpenF1Service
+	db            *gorm.DB
+}
+
+func NewStrategyHandler(openF1Service OpenF1Service, db *gorm.DB) *StrategyHandler {
+	return &StrategyHandler{
+		openF1Service: openF1Service,
+		db:            db,
+	}
+}
+
+// SimulateRequest is the body of a strategy simulation. Anything left out is
+// filled in from the circuit's stored races where possible.
+type SimulateRequest struct {
+	RaceID      uint                               `json:"race_id"`
+	CircuitID   uint                               `json:"circuit_id"`
+	TotalLaps   int                                `json:"total_laps"`
+	PitLoss     float64                            `json:"pit_loss"`
+	BaseLapTime float64                            `json:"base_lap_time"`
+	Fuel        *services.FuelCorrection           `json:"fuel"`
+	Compounds   map[string]services.CompoundParams `json:"compounds"`
+	Plans       []services.StrategyPlan            `json:"plans" binding:"required"`
+}
+
+// Simulate runs candidate pit stop plans and ranks them by total race time
+func (h *StrategyHandler) Simulate(c *gin.Context) {
+	var req SimulateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body",
+		})
+		return
+	}
+
+	circuitID := req.CircuitID
+	if req.RaceID != 0 {
+		var race models.Race
+		result := h.db.First(&race, req.RaceID)
+		if result.Error != nil {
+			if result.Error == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Race not found",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch race from database",
+			})
+			return
+		}
+		circuitID = race.CircuitID
+		if req.TotalLaps == 0 {
+			req.TotalLaps = race.Laps
+		}
+	}
+
+	input := services.StrategyInput{
+		TotalLaps:   req.TotalLaps,
+		PitLoss:     req.PitLoss,
+		BaseLapTime: req.BaseLapTime,
+		Fuel:        req.Fuel,
+		Compounds:   make(map[string]services.CompoundParams),
+		Plans:       req.Plans,
+	}
+
+	// Fill the gaps from what this circuit has produced before
+	defaultsUsed := []string{}
+	if circuitID != 0 {
+		history, err := circuitHistory(h.db, circuitID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch circuit history from database",
+			})
+			return
+		}
+		if input.TotalLaps == 0 && history.totalLaps > 0 {
+			input.TotalLaps = history.totalLaps
+			defaultsUsed = append(defaultsUsed, "total_laps")
+		}
+		if input.PitLoss == 0 && history.pitLoss > 0 {
+			input.PitLoss = history.pitLoss
+			defaultsUsed = append(defaultsUsed, "pit_loss")
+		}
+		if input.BaseLapTime == 0 && history.baseLapTime > 0 {
+			input.BaseLapTime = history.baseLapTime
+			defaultsUsed = append(defaultsUsed, "base_lap_time")
+		}
+		for compound, params := range history.compounds {
+			input.Compounds[compound] = params
+		}
+		if len(history.compounds) > 0 {
+			defaultsUsed = append(defaultsUsed, "compounds")
+		}
+	}
+	for compound, params := range req.Compounds {
+		input.Compounds[strings.ToUpper(compound)] = params
+	}
+
+	results, err := services.SimulateStrategies(input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total_laps":    input.TotalLaps,
+		"pit_loss":      input.PitLoss,
+		"base_lap_time": input.BaseLapTime,
+		"compounds":     input.Compounds,
+		"defaults_used": defaultsUsed,
+		"plans":         results,
+	})
+}
+
+// strategyHistory holds simulator defaults learned from previous races at a circuit
+type strategyHistory struct {
+	totalLaps   int
+	pitLoss     float64
+	baseLapTime float64
+	compounds   map[string]services.CompoundParams
+}
+
+// circuitHistory fits degradation to every stored race at the circuit and
+// pools the stints, pit losses and race lengths into simulator defaults
+func circuitHistory(db *gorm.DB, circuitID uint) (strategyHistory, error) {
+	var history strategyHistory
+
+	var races []models.Race
+	if err := db.Where("circuit_id = ?", circuitID).Order("date DESC").Find(&races).Error; err != nil {
+		return history, err
+	}
+
+	var stints []services.StintDegradation
+	var losses []float64
+	for _, race := range races {
+		laps, err := loadRaceLaps(db, race.ID)
+		if err != nil {
+			return history, err
+		}
+		if len(laps) == 0 {
+			continue
+		}
+		var raceStints []models.Stint
+		if err := db.Where("race_id = ?", race.ID).Find(&raceStints).Error; err != nil {
+			return history, err
+		}
+
+		if history.totalLaps == 0 {
+			history.totalLaps = raceDistanceLaps(race, laps)
+		}
+		fuel := &services.FuelCorrection{
+			KgPerLap:     services.DefaultFuelKgPerLap,
+			SecondsPerKg: services.DefaultSecondsPerKg,
+			TotalLaps:    raceDistanceLaps(race, laps),
+		}
+		analysis := services.AnalyseDegradation(laps, raceStints, fuel, services.DegradationLinear)
+		stints = append(stints, analysis.Stints...)
+		losses = append(losses, services.EstimatePitLoss(laps)...)
+	}
+
+	history.pitLoss = services.Median(losses)
+	history.baseLapTime, history.compounds = services.StrategyDefaults(stints)
+	return history, nil
+}
@@ -5,8 +5,10 @@ import (
 	"strconv"
 
 	"github.com/f1-analytics/models"
+	"github.com/f1-analytics/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TeamHandler struct {
@@ -81,7 +83,7 @@ func (h *TeamHandler) GetTeam(c *gin.Context) {
 	}
 
 	var team models.Team
-	result := h.db.First(&team, teamID)
+	result := h.db.Preload("Seasons").First(&team, teamID)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -123,14 +125,223 @@ func (h *TeamHandler) GetTeamStats(c *gin.Context) {
 		return
 	}
 
-	stats := gin.H{
-		"worldTitles": team.WorldTitles,
-		"raceWins":    team.RaceWins,
-		"poles":       team.Poles,
-		"fastestLaps": team.FastestLaps,
-		"podiums":     team.Podiums,
-		"points":      team.Points,
+	span, err := parseSeasonRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Statistics are derived from the stored results rather than the career columns
+	seasons, err := loadSeasonRange(h.db, span)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch results from database",
+		})
+		return
+	}
+	entries, err := statEntries(h.db, seasons, func(result models.RaceDriver) bool {
+		return result.TeamID == team.ID
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch results from database",
+		})
+		return
+	}
+
+	// Count a title for every finished season the team topped
+	worldTitles := 0
+	for _, season := range seasons {
+		if !seasonComplete(season) {
+			continue
+		}
+		standings := services.ComputeConstructorStandings(season, 0)
+		if len(standings) > 0 && standings[0].TeamID == team.ID {
+			worldTitles++
+		}
+	}
+
+	stats := services.ComputeStats(entries)
+	c.JSON(http.StatusOK, gin.H{
+		"worldTitles": worldTitles,
+		"raceWins":    stats.Wins,
+		"poles":       stats.Poles,
+		"fastestLaps": stats.FastestLaps,
+		"podiums":     stats.Podiums,
+		"points":      stats.Points,
+		"starts":      stats.Starts,
+		"dnfs":        stats.DNFs,
+		"bestFinish":  stats.BestFinish,
+		"bySeason":    stats.BySeason,
+		"byCircuit":   stats.ByCircuit,
+	})
+}
+
+// findOrCreateTeam looks a team up by name, creating it if needed, and records
+// its colour for the given season
+func findOrCreateTeam(db *gorm.DB, name string, color string, season int) (*models.Team, error) {
+	team := models.Team{
+		Name:         name,
+		Active:       true,
+		Nationality:  "Unknown", // Required field, set default
+		BaseLocation: "Unknown",
+	}
+	if err := db.Where("name = ?", name).FirstOrCreate(&team).Error; err != nil {
+		return nil, err
+	}
+
+	teamSeason := models.TeamSeason{
+		TeamID:    team.ID,
+		Season:    season,
+		Color:     color,
+		PowerUnit: services.PowerUnitSupplier(name, season),
+	}
+	var columns []string
+	if teamSeason.Color != "" {
+		columns = append(columns, "color")
+	}
+	if teamSeason.PowerUnit != "" {
+		columns = append(columns, "power_unit")
+	}
+	if len(columns) == 0 {
+		return &team, nil
+	}
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "team_id"}, {Name: "season"}},
+		DoUpdates: clause.AssignmentColumns(append(columns, "updated_at")),
+	}).Create(&teamSeason).Error; err != nil {
+		return nil, err
+	}
+	if color == "" {
+		return &team, nil
+	}
+
+	// Keep the team's headline colour in line with its latest season
+	var latest models.TeamSeason
+	if err := db.Where("team_id = ?", team.ID).Order("season DESC").First(&latest).Error; err != nil {
+		return nil, err
+	}
+	if team.Color != latest.Color {
+		team.Color = latest.Color
+		if err := db.Model(&team).Update("color", latest.Color).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &team, nil
+}
+
+// GetTeammates returns the teammate battles of a team over a season
+func (h *TeamHandler) GetTeammates(c *gin.Context) {
+	teamID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid team ID",
+		})
+		return
+	}
+
+	year := services.GetCurrentSeason()
+	if seasonStr := c.Query("season"); seasonStr != "" {
+		year, err = strconv.Atoi(seasonStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid season",
+			})
+			return
+		}
+	}
+
+	var team models.Team
+	result := h.db.First(&team, teamID)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Team not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch team from database",
+		})
+		return
+	}
+
+	// Teammates are whoever raced for the team in the same round
+	season, err := loadSeasonResults(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch season results from database",
+		})
+		return
+	}
+
+	var raceIDs []uint
+	for _, race := range season.Races {
+		raceIDs = append(raceIDs, race.ID)
+	}
+	var qualifying []models.QualifyingResult
+	if len(raceIDs) > 0 {
+		if err := h.db.Where("race_id IN ?", raceIDs).Find(&qualifying).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch qualifying results from database",
+			})
+			return
+		}
+	}
+	qualifyingByRace := make(map[uint]map[uint]models.QualifyingResult)
+	for _, q := range qualifying {
+		if qualifyingByRace[q.RaceID] == nil {
+			qualifyingByRace[q.RaceID] = make(map[uint]models.QualifyingResult)
+		}
+		qualifyingByRace[q.RaceID][q.DriverID] = q
+	}
+
+	var rounds []services.TeammateRound
+	for _, race := range season.Races {
+		round := services.TeammateRound{
+			Round:      race.Round,
+			Qualifying: qualifyingByRace[race.ID],
+		}
+		for _, result := range season.Results[race.ID] {
+			if result.TeamID == team.ID {
+				round.Results = append(round.Results, result)
+			}
+		}
+		if len(round.Results) > 1 {
+			rounds = append(rounds, round)
+		}
+	}
+
+	drivers, err := loadSeasonDrivers(h.db, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch drivers from database",
+		})
+		return
+	}
+
+	type TeammateResponse struct {
+		services.TeammatePairing
+		DriverAInfo SeasonDriver `json:"driver_a"`
+		DriverBInfo SeasonDriver `json:"driver_b"`
+	}
+
+	battles := services.TeammateBattles(rounds)
+	response := make([]TeammateResponse, len(battles))
+	for i, battle := range battles {
+		response[i] = TeammateResponse{
+			TeammatePairing: battle,
+			DriverAInfo:     drivers[battle.DriverA],
+			DriverBInfo:     drivers[battle.DriverB],
+		}
 	}
 
-	c.JSON(http.StatusOK, stats)
+	c.JSON(http.StatusOK, gin.H{
+		"season":    year,
+		"team":      team.Name,
+		"teammates": response,
+	})
 }
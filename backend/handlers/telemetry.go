@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/f1-analytics/models"
+	"github.com/f1-analytics/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TelemetryHandler struct {
+	openF1Service OpenF1Service
+	db            *gorm.DB
+}
+
+func NewTelemetryHandler(openF1Service OpenF1Service, db *gorm.DB) *TelemetryHandler {
+	return &TelemetryHandler{
+		openF1Service: openF1Service,
+		db:            db,
+	}
+}
+
+// lapRef names one lap of one driver as driver:lap, the driver by car number
+type lapRef struct {
+	DriverNumber int `json:"driver_number"`
+	Lap          int `json:"lap"`
+}
+
+// parseLapRef reads a driver:lap query value
+func parseLapRef(value string) (lapRef, error) {
+	driverStr, lapStr, ok := strings.Cut(value, ":")
+	if !ok {
+		return lapRef{}, fmt.Errorf("invalid lap %q, expected driver:lap", value)
+	}
+	driverNumber, err := strconv.Atoi(driverStr)
+	if err != nil {
+		return lapRef{}, fmt.Errorf("invalid driver number %q", driverStr)
+	}
+	lap, err := strconv.Atoi(lapStr)
+	if err != nil || lap < 1 {
+		return lapRef{}, fmt.Errorf("invalid lap number %q", lapStr)
+	}
+	return lapRef{DriverNumber: driverNumber, Lap: lap}, nil
+}
+
+// CompareLaps overlays two laps' telemetry on distance with a running delta and
+// the time gained or lost per corner
+func (h *TelemetryHandler) CompareLaps(c *gin.Context) {
+	sessionKey, err := strconv.Atoi(c.Query("session"))
+	if err != nil || sessionKey <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid session key",
+		})
+		return
+	}
+
+	refA, err := parseLapRef(c.Query("a"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	refB, err := parseLapRef(c.Query("b"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	step := services.DefaultTraceStep
+	if stepStr := c.Query("step"); stepStr != "" {
+		step, err = strconv.ParseFloat(stepStr, 64)
+		if err != nil || step < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid step",
+			})
+			return
+		}
+	}
+
+	traces := make([][]services.TelemetryPoint, 2)
+	for i, ref := range []lapRef{refA, refB} {
+		trace, ok := h.lapTrace(c, sessionKey, ref)
+		if !ok {
+			return
+		}
+		traces[i] = trace
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"session_key": sessionKey,
+		"a":           refA,
+		"b":           refB,
+		"comparison":  services.CompareLaps(traces[0], traces[1], step),
+	})
+}
+
+// lapTrace loads a lap's telemetry on distance, fetching it from OpenF1 when
+// it is not stored yet. It writes the error response itself and reports
+// whether the caller can carry on.
+func (h *TelemetryHandler) lapTrace(c *gin.Context, sessionKey int, ref lapRef) ([]services.TelemetryPoint, bool) {
+	var driver models.Driver
+	if err := h.db.First(&driver, "number = ?", ref.DriverNumber).Error; err != nil {
+		respondDriverLookupError(c, err)
+		return nil, false
+	}
+
+	// If no car data for the lap in database, fetch from OpenF1 API and store it
+	if err := ensureLapCarData(h.db, h.openF1Service, sessionKey, driver, ref.Lap); err != nil {
+		if err == errLapNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": fmt.Sprintf("No telemetry for driver %d lap %d", ref.DriverNumber, ref.Lap),
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch car data from API",
+		})
+		return nil, false
+	}
+
+	var samples []models.CarDataSample
+	if err := h.db.Where("session_key = ? AND driver_id = ? AND lap_number = ?", sessionKey, driver.ID, ref.Lap).
+		Order("date").Find(&samples).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch car data from database",
+		})
+		return nil, false
+	}
+	return services.DistanceTrace(samples), true
+}
+
+// GetMetrics returns braking, throttle and DRS metrics per lap for the drivers
+// of a session. Laps not stored yet are fetched when a driver and ?laps= are
+// given.
+func (h *TelemetryHandler) GetMetrics(c *gin.Context) {
+	sessionKey, err := strconv.Atoi(c.Query("session"))
+	if err != nil || sessionKey <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid session key",
+		})
+		return
+	}
+
+	query := h.db.Where("session_key = ?", sessionKey)
+	if driverStr := c.Query("driver"); driverStr != "" {
+		number, err := strconv.Atoi(driverStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid driver number",
+			})
+			return
+		}
+		var driver models.Driver
+		if err := h.db.First(&driver, "number = ?", number).Error; err != nil {
+			respondDriverLookupError(c, err)
+			return
+		}
+		query = query.Where("driver_id = ?", driver.ID)
+
+		if lapsStr := c.Query("laps"); lapsStr != "" {
+			var laps []int
+			for _, lapStr := range strings.Split(lapsStr, ",") {
+				lap, err := strconv.Atoi(strings.TrimSpace(lapStr))
+				if err != nil || lap < 1 {
+					c.JSON(http.StatusBadRequest, gin.H{
+						"error": fmt.Sprintf("Invalid lap number %q", lapStr),
+					})
+					return
+				}
+				laps = append(laps, lap)
+			}
+
+			// If no car data for the laps in database, fetch from OpenF1 API and store it
+			if err := ensureLapCarData(h.db, h.openF1Service, sessionKey, driver, laps...); err != nil {
+				if err == errLapNotFound {
+					c.JSON(http.StatusNotFound, gin.H{
+						"error": fmt.Sprintf("No telemetry for driver %d on laps %s", number, lapsStr),
+					})
+					return
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to fetch car data from API",
+				})
+				return
+			}
+			query = query.Where("lap_number IN ?", laps)
+		}
+	}
+
+	var samples []models.CarDataSample
+	if err := query.Order("date").Find(&samples).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch car data from database",
+		})
+		return
+	}
+
+	byLap := make(map[uint]map[int][]models.CarDataSample)
+	for _, sample := range samples {
+		if byLap[sample.DriverID] == nil {
+			byLap[sample.DriverID] = make(map[int][]models.CarDataSample)
+		}
+		byLap[sample.DriverID][sample.LapNumber] = append(byLap[sample.DriverID][sample.LapNumber], sample)
+	}
+
+	driverIDs := make([]uint, 0, len(byLap))
+	for driverID := range byLap {
+		driverIDs = append(driverIDs, driverID)
+	}
+	var drivers []models.Driver
+	if len(driverIDs) > 0 {
+		if err := h.db.Find(&drivers, driverIDs).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch drivers from database",
+			})
+			return
+		}
+	}
+
+	type DrivingStyleResponse struct {
+		services.DrivingStyle
+		DriverNumber int    `json:"driver_number"`
+		Name         string `json:"name"`
+		NameAcronym  string `json:"name_acronym"`
+	}
+
+	response := make([]DrivingStyleResponse, 0, len(drivers))
+	for _, driver := range drivers {
+		var laps []services.LapMetrics
+		for lapNumber, lapSamples := range byLap[driver.ID] {
+			laps = append(laps, services.AnalyseLapTelemetry(lapNumber, services.DistanceTrace(lapSamples)))
+		}
+		response = append(response, DrivingStyleResponse{
+			DrivingStyle: services.SummariseDrivingStyle(driver.ID, laps),
+			DriverNumber: driver.Number,
+			Name:         driver.Name,
+			NameAcronym:  driver.NameAcronym,
+		})
+	}
+	sort.Slice(response, func(i, j int) bool { return response[i].DriverNumber < response[j].DriverNumber })
+
+	c.JSON(http.StatusOK, gin.H{
+		"session_key": sessionKey,
+		"drivers":     response,
+	})
+}
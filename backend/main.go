@@ -38,6 +38,14 @@ func main() {
 	driverHandler := handlers.NewDriverHandler(openF1Service, db)
 	teamHandler := handlers.NewTeamHandler(openF1Service, db)
 	raceHandler := handlers.NewRaceHandler(openF1Service, db)
+	standingsHandler := handlers.NewStandingsHandler(openF1Service, db)
+	compareHandler := handlers.NewCompareHandler(openF1Service, db)
+	strategyHandler := handlers.NewStrategyHandler(openF1Service, db)
+	seasonHandler := handlers.NewSeasonHandler(openF1Service, db)
+	circuitHandler := handlers.NewCircuitHandler(openF1Service, db)
+	telemetryHandler := handlers.NewTelemetryHandler(openF1Service, db)
+	ratingHandler := handlers.NewRatingHandler(openF1Service, db)
+	predictionHandler := handlers.NewPredictionHandler(openF1Service, db)
 
 	// Initialize router
 	router := gin.Default()
@@ -59,16 +67,60 @@ func main() {
 		api.GET("/drivers", driverHandler.GetDrivers)
 		api.GET("/drivers/:id", driverHandler.GetDriver)
 		api.GET("/drivers/:id/stats", driverHandler.GetDriverStats)
+		api.GET("/drivers/:id/rating-history", ratingHandler.GetDriverRatingHistory)
 
 		// Team routes
 		api.GET("/teams", teamHandler.GetTeams)
 		api.GET("/teams/:id", teamHandler.GetTeam)
 		api.GET("/teams/:id/stats", teamHandler.GetTeamStats)
+		api.GET("/teams/:id/teammates", teamHandler.GetTeammates)
 
 		// Race routes
 		api.GET("/races", raceHandler.GetRaces)
 		api.GET("/races/:id", raceHandler.GetRace)
 		api.GET("/races/:id/results", raceHandler.GetRaceResults)
+		api.GET("/races/:id/pace", raceHandler.GetRacePace)
+		api.GET("/races/:id/degradation", raceHandler.GetRaceDegradation)
+		api.GET("/races/:id/pit-exchanges", raceHandler.GetRacePitExchanges)
+		api.GET("/races/:id/sectors", raceHandler.GetRaceSectors)
+		api.GET("/races/:id/speeds", raceHandler.GetRaceSpeeds)
+		api.GET("/races/:id/overtakes", raceHandler.GetRaceOvertakes)
+		api.GET("/races/:id/gaps", raceHandler.GetRaceGaps)
+		api.GET("/races/:id/lapchart", raceHandler.GetRaceLapChart)
+		api.GET("/races/:id/prediction", predictionHandler.GetRacePrediction)
+
+		// Circuit routes
+		api.GET("/circuits/overtaking", circuitHandler.GetOvertakingDifficulty)
+
+		// Season routes
+		api.GET("/seasons/:year/standings/drivers", standingsHandler.GetDriverStandings)
+		api.GET("/seasons/:year/standings/constructors", standingsHandler.GetConstructorStandings)
+		api.GET("/seasons/:year/standings/what-if", standingsHandler.GetWhatIfStandings)
+		api.GET("/seasons/:year/scenarios", standingsHandler.GetScenarios)
+		api.GET("/seasons/:year/projection", standingsHandler.GetProjection)
+		api.GET("/seasons/:year/pit-exchanges", seasonHandler.GetPitExchanges)
+		api.GET("/seasons/:year/speeds", seasonHandler.GetSeasonSpeeds)
+		api.GET("/seasons/:year/overtakes", seasonHandler.GetSeasonOvertakes)
+		api.GET("/seasons/:year/teams/performance", seasonHandler.GetTeamPerformance)
+		api.GET("/seasons/:year/reliability", seasonHandler.GetReliability)
+		api.GET("/seasons/:year/starts", seasonHandler.GetSeasonStarts)
+		api.GET("/seasons/:year/predictions/backtest", predictionHandler.GetBacktest)
+
+		// Points system routes
+		api.GET("/points-systems", standingsHandler.GetPointsSystems)
+
+		// Comparison routes
+		api.GET("/compare/drivers", compareHandler.CompareDrivers)
+
+		// Rating routes
+		api.GET("/ratings/drivers", ratingHandler.GetDriverRatings)
+
+		// Telemetry routes
+		api.GET("/telemetry/compare", telemetryHandler.CompareLaps)
+		api.GET("/telemetry/metrics", telemetryHandler.GetMetrics)
+
+		// Strategy routes
+		api.POST("/strategy/simulate", strategyHandler.Simulate)
 	}
 
 	// Start server
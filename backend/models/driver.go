@@ -9,7 +9,12 @@ import (
 type Driver struct {
 	gorm.Model
 	Name            string    `gorm:"not null"`
+	FirstName       string
+	LastName        string
+	FullName        string
+	NameAcronym     string    // Three-letter code, e.g. VER
 	Nationality     string    `gorm:"not null"`
+	CountryCode     string
 	DateOfBirth     time.Time `gorm:"not null"`
 	Number          int       `gorm:"unique"`
 	TeamID          uint      `gorm:"not null"`
@@ -22,20 +27,109 @@ type Driver struct {
 	CareerPodiums   int       `gorm:"default:0"`
 	Active          bool      `gorm:"default:true"`
 	ProfileImageURL string
+	HeadshotURL     string
 	Biography       string
+	Seasons         []DriverSeason `gorm:"foreignKey:DriverID"`
+}
+
+// DriverSeason holds the presentation data that can change from one season to
+// the next
+type DriverSeason struct {
+	DriverID    uint      `gorm:"primaryKey"`
+	Season      int       `gorm:"primaryKey"`
+	TeamID      uint
+	Team        Team      `gorm:"foreignKey:TeamID"`
+	NameAcronym string
+	HeadshotURL string
+	TeamColor   string    // Hex colour without the leading #, as published by OpenF1
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// DriverRating is a driver's rating after a race
+type DriverRating struct {
+	DriverID    uint      `gorm:"primaryKey"`
+	RaceID      uint      `gorm:"primaryKey"`
+	Season      int       `gorm:"not null;index"`
+	Round       int       `gorm:"not null"`
+	Date        time.Time `gorm:"index"`
+	Rating      float64
+	Change      float64   // Including any regression to the mean at the start of the season
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// RacePrediction is a driver's predicted result for a race, made from what was
+// known before it
+type RacePrediction struct {
+	DriverID          uint      `gorm:"primaryKey"`
+	RaceID            uint      `gorm:"primaryKey"`
+	TeamID            uint
+	Model             string    `gorm:"not null"` // Prediction model the prediction came from
+	Grid              int
+	GridFactor        *float64  // Position each input pointed to, null when it was not available
+	FormFactor        *float64
+	CircuitFactor     *float64
+	TeamPaceFactor    *float64
+	Score             float64
+	DNFProbability    float64
+	ExpectedPosition  float64
+	WinProbability    float64
+	PodiumProbability float64
+	PointsProbability float64
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// PredictedPosition is a driver's predicted chance of finishing a race in one
+// position
+type PredictedPosition struct {
+	DriverID    uint    `gorm:"primaryKey"`
+	RaceID      uint    `gorm:"primaryKey"`
+	Position    int     `gorm:"primaryKey"`
+	Probability float64
 }
 
 // RaceDriver represents the many-to-many relationship between drivers and races
 type RaceDriver struct {
+	DriverID       uint      `gorm:"primaryKey"`
+	RaceID         uint      `gorm:"primaryKey"`
+	TeamID         uint      `gorm:"index"` // Team driven for in this race
+	Position       int
+	Points         float64
+	Grid           int
+	FastestLap     time.Duration
+	RaceTime       time.Duration
+	Status         string    // Finished, DNF, DNS, etc.
+	SprintPosition int       // Zero when the weekend had no sprint
+	SprintPoints   float64
+	SprintStatus   string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+}
+
+// QualifyingResult holds a driver's qualifying classification for a race weekend
+type QualifyingResult struct {
 	DriverID    uint      `gorm:"primaryKey"`
 	RaceID      uint      `gorm:"primaryKey"`
+	TeamID      uint      `gorm:"index"`
 	Position    int
-	Points      float64
-	Grid        int
-	FastestLap  time.Duration
-	RaceTime    time.Duration
-	Status      string    // Finished, DNF, DNS, etc.
+	Q1          time.Duration
+	Q2          time.Duration
+	Q3          time.Duration
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 	DeletedAt   gorm.DeletedAt `gorm:"index"`
-} 
\ No newline at end of file
+}
+
+// BestLap returns the fastest of the driver's qualifying laps
+func (q QualifyingResult) BestLap() time.Duration {
+	best := time.Duration(0)
+	for _, lap := range []time.Duration{q.Q1, q.Q2, q.Q3} {
+		if lap > 0 && (best == 0 || lap < best) {
+			best = lap
+		}
+	}
+	return best
+}
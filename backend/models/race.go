@@ -36,7 +36,10 @@ type Race struct {
 	Practice3Time   time.Time
 	SprintTime      time.Time // Optional, for sprint races
 	Status          string    // Scheduled, Completed, Cancelled
-	Laps            int
+	MeetingKey      int       // OpenF1 meeting, zero when the race did not come from OpenF1
+	SessionKey      int       // OpenF1 race session
+	Laps            int       // Scheduled race laps, zero when not known
+	LapsCompleted   int       // Laps run by the winner, zero until the results are in
 	RaceDistance    float64   // Total race distance in kilometers
 	Weather         string
 	Temperature     float64
@@ -47,18 +50,104 @@ type Race struct {
 	TeamResults     []RaceTeam
 }
 
-// Lap represents individual lap times for a driver in a race
+// Session names as published by OpenF1
+const (
+	SessionPractice1        = "Practice 1"
+	SessionPractice2        = "Practice 2"
+	SessionPractice3        = "Practice 3"
+	SessionQualifying       = "Qualifying"
+	SessionSprintQualifying = "Sprint Qualifying"
+	SessionSprint           = "Sprint"
+	SessionRace             = "Race"
+)
+
+// Lap represents individual lap times for a driver in any session of a race weekend
 type Lap struct {
 	gorm.Model
-	RaceID      uint      `gorm:"not null"`
-	DriverID    uint      `gorm:"not null"`
-	LapNumber   int       `gorm:"not null"`
-	LapTime     time.Duration
-	Position    int
-	IsFastest   bool      `gorm:"default:false"`
-	PitStop     bool      `gorm:"default:false"`
-	PitStopTime time.Duration
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	DeletedAt   gorm.DeletedAt `gorm:"index"`
-} 
\ No newline at end of file
+	RaceID           uint          `gorm:"not null"`
+	DriverID         uint          `gorm:"not null"`
+	Session          string        `gorm:"not null;default:Race;index"` // OpenF1 session name, e.g. Qualifying
+	LapNumber        int           `gorm:"not null"`
+	DateStart        time.Time     // Zero when OpenF1 did not publish it, as for most opening laps
+	LapTime          time.Duration
+	Sector1          time.Duration
+	Sector2          time.Duration
+	Sector3          time.Duration
+	I1Speed          int           // Intermediate speeds and speed trap, km/h
+	I2Speed          int
+	SpeedTrap        int
+	Position         int
+	IsFastest        bool          `gorm:"default:false"`
+	PitStop          bool          `gorm:"default:false"` // Driver pitted at the end of this lap
+	PitStopTime      time.Duration
+	PitOutLap        bool          `gorm:"default:false"`
+	SafetyCar        bool          `gorm:"default:false"` // Lap run at least partly behind the safety car
+	VirtualSafetyCar bool          `gorm:"default:false"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	DeletedAt        gorm.DeletedAt `gorm:"index"`
+}
+
+// Stint represents a run on a single set of tyres
+type Stint struct {
+	gorm.Model
+	RaceID         uint      `gorm:"not null;index"`
+	DriverID       uint      `gorm:"not null"`
+	StintNumber    int       `gorm:"not null"`
+	Compound       string    // SOFT, MEDIUM, HARD, INTERMEDIATE, WET
+	LapStart       int
+	LapEnd         int
+	TyreAgeAtStart int       // Laps already on the tyres when the stint began
+}
+
+// PositionSample is a driver's running position from a moment of the race
+// onwards, as published by OpenF1
+type PositionSample struct {
+	gorm.Model
+	RaceID   uint      `gorm:"not null;index"`
+	DriverID uint      `gorm:"not null"`
+	Date     time.Time `gorm:"not null"`
+	Position int       `gorm:"not null"`
+}
+
+// IntervalSample is a driver's gap to the leader and to the car ahead at a
+// moment of the race, as published by OpenF1
+type IntervalSample struct {
+	gorm.Model
+	RaceID           uint      `gorm:"not null;index"`
+	DriverID         uint      `gorm:"not null"`
+	Date             time.Time `gorm:"not null"`
+	GapToLeader      float64   // Seconds, zero for the leader
+	LapsBehindLeader int       // Set instead of GapToLeader once lapped
+	Interval         float64   // Seconds to the car ahead
+	LapsBehindAhead  int       // Set instead of Interval when the car ahead is a lap or more up
+}
+
+// RaceControlMessage is a message from race control during a race, as published
+// by OpenF1
+type RaceControlMessage struct {
+	gorm.Model
+	RaceID    uint      `gorm:"not null;index"`
+	Date      time.Time
+	LapNumber int       // Leader's lap when the message was sent
+	Category  string    // SafetyCar, Flag, Drs, CarEvent, Other
+	Flag      string    // GREEN, YELLOW, RED, CHEQUERED, ...
+	Scope     string    // Track, Sector or Driver
+	Message   string
+}
+
+// CarDataSample is one telemetry sample from a single lap, as published by
+// OpenF1 at about 4 Hz
+type CarDataSample struct {
+	gorm.Model
+	SessionKey int       `gorm:"not null;index:idx_car_data_lap"` // OpenF1 session, so any session of a weekend can be stored
+	DriverID   uint      `gorm:"not null;index:idx_car_data_lap"`
+	LapNumber  int       `gorm:"not null;index:idx_car_data_lap"`
+	Date       time.Time `gorm:"not null"`
+	Speed      int       // km/h
+	RPM        int
+	Gear       int
+	Throttle   int       // Percent
+	Brake      int       // 0 or 100
+	DRS        int       // OpenF1 DRS code, open at 10 and above
+}
@@ -1,9 +1,11 @@
 package models
 
 import (
+	"sort"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Team struct {
@@ -26,8 +28,20 @@ type Team struct {
 	Active          bool      `gorm:"default:true"`
 	LogoURL         string
 	Website         string
+	Color           string    // Hex colour of the most recent season, without the leading #
 	Drivers         []Driver  `gorm:"foreignKey:TeamID"`
 	Races           []Race    `gorm:"many2many:race_teams;"`
+	Seasons         []TeamSeason `gorm:"foreignKey:TeamID"`
+}
+
+// TeamSeason holds the presentation data that can change from one season to the next
+type TeamSeason struct {
+	TeamID      uint      `gorm:"primaryKey"`
+	Season      int       `gorm:"primaryKey"`
+	Color       string
+	PowerUnit   string    // Engine supplier that season
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
 }
 
 // RaceTeam represents the many-to-many relationship between teams and races
@@ -39,4 +53,72 @@ type RaceTeam struct {
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 	DeletedAt   gorm.DeletedAt `gorm:"index"`
-} 
\ No newline at end of file
+}
+
+// SyncRaceTeams rebuilds the constructor results of a race from its driver
+// results. Call it once a race's results are stored or changed. Drivers without
+// a team on the result fall back to the team recorded for them that season.
+func SyncRaceTeams(tx *gorm.DB, raceID uint) error {
+	if raceID == 0 {
+		return nil
+	}
+
+	var race Race
+	if err := tx.First(&race, raceID).Error; err != nil {
+		return err
+	}
+
+	var results []RaceDriver
+	if err := tx.Where("race_id = ?", raceID).Find(&results).Error; err != nil {
+		return err
+	}
+
+	points := make(map[uint]float64)
+	for _, result := range results {
+		teamID, err := resultTeamID(tx, result, race.Season)
+		if err != nil {
+			return err
+		}
+		if teamID == 0 {
+			continue
+		}
+		points[teamID] += result.Points + result.SprintPoints
+	}
+
+	teams := make([]RaceTeam, 0, len(points))
+	for teamID, total := range points {
+		teams = append(teams, RaceTeam{TeamID: teamID, RaceID: raceID, Points: total})
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		if teams[i].Points != teams[j].Points {
+			return teams[i].Points > teams[j].Points
+		}
+		return teams[i].TeamID < teams[j].TeamID
+	})
+	for i := range teams {
+		teams[i].Position = i + 1
+	}
+
+	// Drop teams that no longer have a result in this race
+	if err := tx.Unscoped().Where("race_id = ?", raceID).Delete(&RaceTeam{}).Error; err != nil {
+		return err
+	}
+	if len(teams) == 0 {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&teams).Error
+}
+
+// resultTeamID resolves the team a driver raced for in a result
+func resultTeamID(tx *gorm.DB, result RaceDriver, season int) (uint, error) {
+	if result.TeamID != 0 {
+		return result.TeamID, nil
+	}
+
+	var driverSeason DriverSeason
+	err := tx.Where("driver_id = ? AND season = ?", result.DriverID, season).Limit(1).Find(&driverSeason).Error
+	if err != nil {
+		return 0, err
+	}
+	return driverSeason.TeamID, nil
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/f1-analytics/models"
+)
+
+// ComparisonSide is what one driver did over a race weekend
+type ComparisonSide struct {
+	Present       bool
+	QualiPosition int // Zero when no qualifying classification is stored
+	Grid          int
+	Position      int
+	Points        float64
+	Status        string
+	Laps          []models.Lap
+}
+
+// ComparisonRound pairs two drivers' weekends for a single round
+type ComparisonRound struct {
+	Round  int
+	RaceID uint
+	Race   string
+	A      ComparisonSide
+	B      ComparisonSide
+}
+
+// HeadToHead counts how often each driver came out ahead
+type HeadToHead struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+// SideTotals are one driver's aggregates over the compared rounds
+type SideTotals struct {
+	Starts        int     `json:"starts"`
+	AverageFinish float64 `json:"average_finish"` // Over classified finishes only
+	Points        float64 `json:"points"`
+}
+
+// RoundOutcome is the per-round result of a comparison
+type RoundOutcome struct {
+	Round           int     `json:"round"`
+	RaceID          uint    `json:"race_id"`
+	Race            string  `json:"race"`
+	QualifyingA     int     `json:"qualifying_a"`
+	QualifyingB     int     `json:"qualifying_b"`
+	FinishA         int     `json:"finish_a"`
+	FinishB         int     `json:"finish_b"`
+	StatusA         string  `json:"status_a"`
+	StatusB         string  `json:"status_b"`
+	QualifyingAhead string  `json:"qualifying_ahead"` // "a", "b" or empty when not comparable
+	RaceAhead       string  `json:"race_ahead"`
+	MedianPaceDelta float64 `json:"median_pace_delta"` // Seconds per lap, negative when A was quicker
+	CommonLaps      int     `json:"common_laps"`
+}
+
+// DriverComparison is the head-to-head summary of two drivers
+type DriverComparison struct {
+	Qualifying      HeadToHead     `json:"qualifying"`
+	Race            HeadToHead     `json:"race"`
+	A               SideTotals     `json:"a"`
+	B               SideTotals     `json:"b"`
+	MedianPaceDelta float64        `json:"median_pace_delta"` // Seconds per lap over every common lap, negative when A was quicker
+	CommonLaps      int            `json:"common_laps"`
+	Rounds          []RoundOutcome `json:"rounds"`
+}
+
+// CompareDrivers builds the head-to-head between two drivers over the given rounds
+func CompareDrivers(rounds []ComparisonRound) DriverComparison {
+	var comparison DriverComparison
+	var allDeltas []float64
+	var finishSumA, finishSumB, finishesA, finishesB int
+
+	for _, round := range rounds {
+		if !round.A.Present && !round.B.Present {
+			continue
+		}
+
+		outcome := RoundOutcome{
+			Round:       round.Round,
+			RaceID:      round.RaceID,
+			Race:        round.Race,
+			QualifyingA: qualifyingPlace(round.A),
+			QualifyingB: qualifyingPlace(round.B),
+			FinishA:     round.A.Position,
+			FinishB:     round.B.Position,
+			StatusA:     round.A.Status,
+			StatusB:     round.B.Status,
+		}
+
+		if round.A.Present {
+			comparison.A.Starts++
+			comparison.A.Points += round.A.Points
+			if round.A.Position > 0 {
+				finishSumA += round.A.Position
+				finishesA++
+			}
+		}
+		if round.B.Present {
+			comparison.B.Starts++
+			comparison.B.Points += round.B.Points
+			if round.B.Position > 0 {
+				finishSumB += round.B.Position
+				finishesB++
+			}
+		}
+
+		if round.A.Present && round.B.Present {
+			// Qualifying: the better qualifying place wins
+			if outcome.QualifyingA > 0 && outcome.QualifyingB > 0 {
+				if outcome.QualifyingA < outcome.QualifyingB {
+					outcome.QualifyingAhead = "a"
+					comparison.Qualifying.A++
+				} else if outcome.QualifyingB < outcome.QualifyingA {
+					outcome.QualifyingAhead = "b"
+					comparison.Qualifying.B++
+				}
+			}
+
+			// Race: a classified finish beats a retirement
+			if ahead := raceAhead(round.A.Position, round.B.Position); ahead != "" {
+				outcome.RaceAhead = ahead
+				if ahead == "a" {
+					comparison.Race.A++
+				} else {
+					comparison.Race.B++
+				}
+			}
+
+			deltas := commonLapDeltas(round.A.Laps, round.B.Laps)
+			outcome.CommonLaps = len(deltas)
+			outcome.MedianPaceDelta = Median(deltas)
+			allDeltas = append(allDeltas, deltas...)
+		}
+
+		comparison.Rounds = append(comparison.Rounds, outcome)
+	}
+
+	if finishesA > 0 {
+		comparison.A.AverageFinish = float64(finishSumA) / float64(finishesA)
+	}
+	if finishesB > 0 {
+		comparison.B.AverageFinish = float64(finishSumB) / float64(finishesB)
+	}
+	comparison.CommonLaps = len(allDeltas)
+	comparison.MedianPaceDelta = Median(allDeltas)
+
+	return comparison
+}
+
+// qualifyingPlace prefers the qualifying classification and falls back to the grid
+func qualifyingPlace(side ComparisonSide) int {
+	if side.QualiPosition > 0 {
+		return side.QualiPosition
+	}
+	return side.Grid
+}
+
+// raceAhead decides who finished ahead, treating an unclassified finish as last
+func raceAhead(positionA, positionB int) string {
+	switch {
+	case positionA > 0 && (positionB == 0 || positionA < positionB):
+		return "a"
+	case positionB > 0 && (positionA == 0 || positionB < positionA):
+		return "b"
+	}
+	return ""
+}
+
+// commonLapDeltas returns A's lap time minus B's, in seconds, for every lap
+// both drivers completed at racing speed
+func commonLapDeltas(lapsA, lapsB []models.Lap) []float64 {
+	byNumber := make(map[int]models.Lap, len(lapsB))
+	for _, lap := range lapsB {
+		byNumber[lap.LapNumber] = lap
+	}
+
+	var deltas []float64
+	for _, lapA := range lapsA {
+		lapB, ok := byNumber[lapA.LapNumber]
+		if !ok || !comparableLap(lapA) || !comparableLap(lapB) {
+			continue
+		}
+		deltas = append(deltas, (lapA.LapTime - lapB.LapTime).Seconds())
+	}
+	return deltas
+}
+
+// comparableLap reports whether a lap reflects racing pace
+func comparableLap(lap models.Lap) bool {
+	return lap.LapTime > 0 && lap.LapNumber > 1 && !lap.PitStop && !lap.PitOutLap &&
+		!lap.SafetyCar && !lap.VirtualSafetyCar
+}
+
+// Median returns the median of the values, or zero when there are none
+func Median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
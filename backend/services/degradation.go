@@ -0,0 +1,360 @@
+package services
+
+import (
+	"math"
+	"sort"
+
+	"github.com/f1-analytics/models"
+)
+
+const (
+	// MinStintFitLaps is the fewest clean laps a stint needs before a model is fitted
+	MinStintFitLaps = 5
+	// CliffSlopeIncrease is how much faster, in seconds per lap, a stint must
+	// degrade after a break point before it counts as a cliff
+	CliffSlopeIncrease = 0.1
+	// cliffMinSegment is the fewest laps on either side of a cliff
+	cliffMinSegment = 3
+)
+
+// DegradationModel selects the regression fitted to a stint
+type DegradationModel string
+
+const (
+	DegradationLinear    DegradationModel = "linear"
+	DegradationQuadratic DegradationModel = "quadratic"
+	// DegradationAuto keeps the quadratic fit only when it explains the stint better
+	DegradationAuto DegradationModel = "auto"
+)
+
+// StintDegradation is the fitted degradation of one driver's stint
+type StintDegradation struct {
+	DriverID     uint      `json:"driver_id"`
+	StintNumber  int       `json:"stint_number"`
+	Compound     string    `json:"compound"`
+	LapStart     int       `json:"lap_start"`
+	LapEnd       int       `json:"lap_end"`
+	TyreAgeStart int       `json:"tyre_age_start"`
+	CleanLaps    int       `json:"clean_laps"`
+	Model        string    `json:"model"`
+	Coefficients []float64 `json:"coefficients"` // Lap time = c0 + c1*age (+ c2*age^2), seconds
+	DegRate      float64   `json:"deg_rate"`     // Seconds lost per lap of tyre age, averaged over the stint
+	CliffLap     int       `json:"cliff_lap,omitempty"`
+	CliffTyreAge int       `json:"cliff_tyre_age,omitempty"`
+	RSquared     float64   `json:"r_squared"`
+	RMSE         float64   `json:"rmse"`
+}
+
+// CompoundDegradation aggregates the stints run on one compound
+type CompoundDegradation struct {
+	Compound         string  `json:"compound"`
+	Stints           int     `json:"stints"`
+	MedianDegRate    float64 `json:"median_deg_rate"`
+	MeanDegRate      float64 `json:"mean_deg_rate"`
+	MedianBasePace   float64 `json:"median_base_pace"` // Fitted lap time on new tyres, seconds
+	AverageStintLaps float64 `json:"average_stint_laps"`
+	Cliffs           int     `json:"cliffs"`
+}
+
+// DegradationAnalysis is the degradation picture of a race
+type DegradationAnalysis struct {
+	FuelCorrection *FuelCorrection       `json:"fuel_correction,omitempty"`
+	Stints         []StintDegradation    `json:"stints"`
+	Compounds      []CompoundDegradation `json:"compounds"`
+}
+
+// AnalyseDegradation fits a degradation model to every stint with enough clean laps
+func AnalyseDegradation(laps []models.Lap, stints []models.Stint, fuel *FuelCorrection, model DegradationModel) DegradationAnalysis {
+	lapsByDriver := make(map[uint][]models.Lap)
+	for _, lap := range laps {
+		lapsByDriver[lap.DriverID] = append(lapsByDriver[lap.DriverID], lap)
+	}
+	for _, driverLaps := range lapsByDriver {
+		sort.Slice(driverLaps, func(i, j int) bool { return driverLaps[i].LapNumber < driverLaps[j].LapNumber })
+	}
+
+	analysis := DegradationAnalysis{FuelCorrection: fuel}
+	for _, stint := range stints {
+		ages, times := stintSeries(lapsByDriver[stint.DriverID], stint, fuel)
+		if len(ages) < MinStintFitLaps {
+			continue
+		}
+		if fit, ok := fitStint(ages, times, model); ok {
+			fit.DriverID = stint.DriverID
+			fit.StintNumber = stint.StintNumber
+			fit.Compound = stint.Compound
+			fit.LapStart = stint.LapStart
+			fit.LapEnd = stint.LapEnd
+			fit.TyreAgeStart = stint.TyreAgeAtStart
+			if fit.CliffTyreAge > 0 {
+				fit.CliffLap = stint.LapStart + fit.CliffTyreAge - stint.TyreAgeAtStart
+			}
+			analysis.Stints = append(analysis.Stints, fit)
+		}
+	}
+	sort.Slice(analysis.Stints, func(i, j int) bool {
+		if analysis.Stints[i].DriverID != analysis.Stints[j].DriverID {
+			return analysis.Stints[i].DriverID < analysis.Stints[j].DriverID
+		}
+		return analysis.Stints[i].StintNumber < analysis.Stints[j].StintNumber
+	})
+
+	analysis.Compounds = AggregateCompounds(analysis.Stints)
+	return analysis
+}
+
+// AggregateCompounds summarises fitted stints per compound
+func AggregateCompounds(stints []StintDegradation) []CompoundDegradation {
+	type acc struct {
+		rates, bases []float64
+		laps, cliffs int
+	}
+	byCompound := make(map[string]*acc)
+	for _, stint := range stints {
+		a, ok := byCompound[stint.Compound]
+		if !ok {
+			a = &acc{}
+			byCompound[stint.Compound] = a
+		}
+		a.rates = append(a.rates, stint.DegRate)
+		if len(stint.Coefficients) > 0 {
+			a.bases = append(a.bases, stint.Coefficients[0])
+		}
+		a.laps += stint.LapEnd - stint.LapStart + 1
+		if stint.CliffLap > 0 {
+			a.cliffs++
+		}
+	}
+
+	compounds := make([]CompoundDegradation, 0, len(byCompound))
+	for compound, a := range byCompound {
+		mean, _ := MeanStdDev(a.rates)
+		compounds = append(compounds, CompoundDegradation{
+			Compound:         compound,
+			Stints:           len(a.rates),
+			MedianDegRate:    Median(a.rates),
+			MeanDegRate:      mean,
+			MedianBasePace:   Median(a.bases),
+			AverageStintLaps: float64(a.laps) / float64(len(a.rates)),
+			Cliffs:           a.cliffs,
+		})
+	}
+	sort.Slice(compounds, func(i, j int) bool { return compounds[i].Compound < compounds[j].Compound })
+	return compounds
+}
+
+// stintSeries returns the tyre age and, optionally fuel corrected, lap time
+// of every representative lap in the stint
+func stintSeries(driverLaps []models.Lap, stint models.Stint, fuel *FuelCorrection) ([]float64, []float64) {
+	var kept []models.Lap
+	for i, lap := range driverLaps {
+		if lap.LapNumber < stint.LapStart || lap.LapNumber > stint.LapEnd {
+			continue
+		}
+		var previous *models.Lap
+		if i > 0 {
+			previous = &driverLaps[i-1]
+		}
+		if RepresentativeLap(lap, previous) {
+			kept = append(kept, lap)
+		}
+	}
+
+	raw := make([]float64, len(kept))
+	for i, lap := range kept {
+		raw[i] = lap.LapTime.Seconds()
+	}
+	limit := Median(raw) * (1 + DefaultOutlierThreshold)
+
+	var ages, times []float64
+	for _, lap := range kept {
+		seconds := lap.LapTime.Seconds()
+		if seconds > limit {
+			continue
+		}
+		if fuel != nil {
+			seconds = fuel.Correct(lap.LapNumber, seconds)
+		}
+		ages = append(ages, float64(stint.TyreAgeAtStart+lap.LapNumber-stint.LapStart))
+		times = append(times, seconds)
+	}
+	return ages, times
+}
+
+// fitStint fits the requested model and looks for a cliff
+func fitStint(ages, times []float64, model DegradationModel) (StintDegradation, bool) {
+	linear, ok := FitPolynomial(ages, times, 1)
+	if !ok {
+		return StintDegradation{}, false
+	}
+	chosen := linear
+	name := DegradationLinear
+
+	if model != DegradationLinear && len(ages) > MinStintFitLaps {
+		if quadratic, ok := FitPolynomial(ages, times, 2); ok {
+			if model == DegradationQuadratic || adjustedR2(quadratic, len(ages)) > adjustedR2(linear, len(ages)) {
+				chosen = quadratic
+				name = DegradationQuadratic
+			}
+		}
+	}
+
+	stint := StintDegradation{
+		CleanLaps:    len(ages),
+		Model:        string(name),
+		Coefficients: chosen.Coefficients,
+		RSquared:     chosen.RSquared,
+		RMSE:         chosen.RMSE,
+		DegRate:      chosen.Coefficients[1],
+	}
+	if name == DegradationQuadratic {
+		// Average slope of the curve across the stint
+		stint.DegRate = chosen.Coefficients[1] + chosen.Coefficients[2]*(ages[0]+ages[len(ages)-1])
+	}
+	stint.CliffTyreAge = findCliff(ages, times, linear)
+
+	return stint, true
+}
+
+// findCliff looks for a break point after which the stint degrades markedly
+// faster, returning the tyre age where it starts or zero when there is none
+func findCliff(ages, times []float64, single PolynomialFit) int {
+	n := len(ages)
+	if n < 2*cliffMinSegment {
+		return 0
+	}
+	singleSSE := single.RMSE * single.RMSE * float64(n)
+
+	bestSSE := math.Inf(1)
+	bestAt := 0
+	for k := cliffMinSegment; k <= n-cliffMinSegment; k++ {
+		before, okBefore := FitPolynomial(ages[:k], times[:k], 1)
+		after, okAfter := FitPolynomial(ages[k:], times[k:], 1)
+		if !okBefore || !okAfter {
+			continue
+		}
+		if after.Coefficients[1]-before.Coefficients[1] < CliffSlopeIncrease {
+			continue
+		}
+		sse := before.RMSE*before.RMSE*float64(k) + after.RMSE*after.RMSE*float64(n-k)
+		if sse < bestSSE {
+			bestSSE = sse
+			bestAt = k
+		}
+	}
+
+	// The split has to explain the stint clearly better than one straight line
+	if bestAt == 0 || bestSSE > singleSSE*0.7 {
+		return 0
+	}
+	return int(ages[bestAt])
+}
+
+// PolynomialFit is a least-squares polynomial fit
+type PolynomialFit struct {
+	Coefficients []float64 // Lowest order first
+	RSquared     float64
+	RMSE         float64
+}
+
+// Predict evaluates the fitted polynomial
+func (p PolynomialFit) Predict(x float64) float64 {
+	y := 0.0
+	for i := len(p.Coefficients) - 1; i >= 0; i-- {
+		y = y*x + p.Coefficients[i]
+	}
+	return y
+}
+
+// FitPolynomial fits y against x by least squares, reporting false when the
+// system is under-determined or singular
+func FitPolynomial(x, y []float64, degree int) (PolynomialFit, bool) {
+	n := len(x)
+	size := degree + 1
+	if n < size || n != len(y) {
+		return PolynomialFit{}, false
+	}
+
+	// Normal equations: (X^T X) c = X^T y
+	matrix := make([][]float64, size)
+	for i := range matrix {
+		matrix[i] = make([]float64, size+1)
+	}
+	for k := 0; k < n; k++ {
+		powers := make([]float64, 2*degree+1)
+		powers[0] = 1
+		for p := 1; p < len(powers); p++ {
+			powers[p] = powers[p-1] * x[k]
+		}
+		for i := 0; i < size; i++ {
+			for j := 0; j < size; j++ {
+				matrix[i][j] += powers[i+j]
+			}
+			matrix[i][size] += powers[i] * y[k]
+		}
+	}
+
+	coefficients, ok := solveLinearSystem(matrix)
+	if !ok {
+		return PolynomialFit{}, false
+	}
+
+	fit := PolynomialFit{Coefficients: coefficients}
+	mean, _ := MeanStdDev(y)
+	var ssRes, ssTot float64
+	for k := 0; k < n; k++ {
+		residual := y[k] - fit.Predict(x[k])
+		ssRes += residual * residual
+		ssTot += (y[k] - mean) * (y[k] - mean)
+	}
+	if ssTot > 0 {
+		fit.RSquared = 1 - ssRes/ssTot
+	}
+	fit.RMSE = math.Sqrt(ssRes / float64(n))
+
+	return fit, true
+}
+
+// adjustedR2 penalises R-squared for the number of fitted terms
+func adjustedR2(fit PolynomialFit, n int) float64 {
+	terms := len(fit.Coefficients) - 1
+	if n-terms-1 <= 0 {
+		return fit.RSquared
+	}
+	return 1 - (1-fit.RSquared)*float64(n-1)/float64(n-terms-1)
+}
+
+// solveLinearSystem solves an augmented matrix by Gaussian elimination with
+// partial pivoting
+func solveLinearSystem(matrix [][]float64) ([]float64, bool) {
+	size := len(matrix)
+	for col := 0; col < size; col++ {
+		pivot := col
+		for row := col + 1; row < size; row++ {
+			if math.Abs(matrix[row][col]) > math.Abs(matrix[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(matrix[pivot][col]) < 1e-12 {
+			return nil, false
+		}
+		matrix[col], matrix[pivot] = matrix[pivot], matrix[col]
+
+		for row := col + 1; row < size; row++ {
+			factor := matrix[row][col] / matrix[col][col]
+			for k := col; k <= size; k++ {
+				matrix[row][k] -= factor * matrix[col][k]
+			}
+		}
+	}
+
+	solution := make([]float64, size)
+	for row := size - 1; row >= 0; row-- {
+		sum := matrix[row][size]
+		for k := row + 1; k < size; k++ {
+			sum -= matrix[row][k] * solution[k]
+		}
+		solution[row] = sum / matrix[row][row]
+	}
+	return solution, true
+}
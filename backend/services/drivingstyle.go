@@ -0,0 +1,146 @@
+package services
+
+import "sort"
+
+const (
+	// FullThrottleLevel is the throttle percentage that counts as flat out
+	FullThrottleLevel = 98.0
+	// CoastingThrottleLevel is the throttle percentage below which a car off the brakes is coasting
+	CoastingThrottleLevel = 5.0
+	// throttleReapplied is the throttle percentage that ends a corner after a braking zone
+	throttleReapplied = 50.0
+)
+
+// BrakingZone is one stop from the first touch of the brake to the corner's
+// slowest point
+type BrakingZone struct {
+	BrakingPoint float64 `json:"braking_point"` // Metres from the start of the lap
+	EntrySpeed   float64 `json:"entry_speed"`
+	MinSpeed     float64 `json:"min_speed"`
+	MinSpeedAt   float64 `json:"min_speed_at"`
+	BrakingTime  float64 `json:"braking_time"` // Seconds on the brake
+}
+
+// DRSActivation is one stretch with the rear wing open
+type DRSActivation struct {
+	Start       float64 `json:"start"` // Metres from the start of the lap
+	End         float64 `json:"end"`
+	StartSpeed  float64 `json:"start_speed"`
+	EndSpeed    float64 `json:"end_speed"`
+	SpeedGained float64 `json:"speed_gained"`
+}
+
+// LapMetrics describes how a lap was driven
+type LapMetrics struct {
+	LapNumber    int             `json:"lap_number"`
+	LapTime      float64         `json:"lap_time"` // From the telemetry
+	TopSpeed     float64         `json:"top_speed"`
+	FullThrottle float64         `json:"full_throttle"` // Percent of the lap
+	CoastingTime float64         `json:"coasting_time"` // Seconds off both pedals
+	BrakingTime  float64         `json:"braking_time"`
+	BrakingZones []BrakingZone   `json:"braking_zones"`
+	DRS          []DRSActivation `json:"drs"`
+}
+
+// DrivingStyle sums up a driver's laps in a session
+type DrivingStyle struct {
+	DriverID            uint         `json:"driver_id"`
+	Laps                []LapMetrics `json:"laps"`
+	AverageFullThrottle float64      `json:"average_full_throttle"`
+	AverageCoastingTime float64      `json:"average_coasting_time"`
+	AverageBrakingTime  float64      `json:"average_braking_time"`
+	AverageDRSGain      float64      `json:"average_drs_gain"`
+}
+
+// AnalyseLapTelemetry derives braking, throttle and DRS metrics from a lap's
+// telemetry. Each sample holds until the next one.
+func AnalyseLapTelemetry(lapNumber int, points []TelemetryPoint) LapMetrics {
+	metrics := LapMetrics{LapNumber: lapNumber, BrakingZones: []BrakingZone{}, DRS: []DRSActivation{}}
+	if len(points) < 2 {
+		return metrics
+	}
+	metrics.LapTime = points[len(points)-1].Time
+
+	fullThrottle := 0.0
+	var zone *BrakingZone
+	var drs *DRSActivation
+	for i, p := range points {
+		metrics.TopSpeed = max(metrics.TopSpeed, p.Speed)
+
+		dt := 0.0
+		if i+1 < len(points) {
+			dt = points[i+1].Time - p.Time
+		}
+		switch {
+		case p.Brake:
+			metrics.BrakingTime += dt
+		case p.Throttle >= FullThrottleLevel:
+			fullThrottle += dt
+		case p.Throttle < CoastingThrottleLevel:
+			metrics.CoastingTime += dt
+		}
+
+		// A zone runs from the brake going on until the throttle is picked up again
+		if p.Brake && zone == nil {
+			zone = &BrakingZone{BrakingPoint: p.Distance, EntrySpeed: p.Speed, MinSpeed: p.Speed, MinSpeedAt: p.Distance}
+		}
+		if zone != nil {
+			if p.Brake {
+				zone.BrakingTime += dt
+			}
+			if p.Speed < zone.MinSpeed {
+				zone.MinSpeed, zone.MinSpeedAt = p.Speed, p.Distance
+			}
+			if !p.Brake && p.Throttle >= throttleReapplied {
+				metrics.BrakingZones = append(metrics.BrakingZones, *zone)
+				zone = nil
+			}
+		}
+
+		if p.DRS && drs == nil {
+			drs = &DRSActivation{Start: p.Distance, StartSpeed: p.Speed}
+		}
+		if drs != nil && (!p.DRS || i == len(points)-1) {
+			drs.End, drs.EndSpeed = p.Distance, p.Speed
+			drs.SpeedGained = drs.EndSpeed - drs.StartSpeed
+			metrics.DRS = append(metrics.DRS, *drs)
+			drs = nil
+		}
+	}
+	if zone != nil {
+		metrics.BrakingZones = append(metrics.BrakingZones, *zone)
+	}
+	if metrics.LapTime > 0 {
+		metrics.FullThrottle = fullThrottle / metrics.LapTime * 100
+	}
+	return metrics
+}
+
+// SummariseDrivingStyle averages a driver's lap metrics
+func SummariseDrivingStyle(driverID uint, laps []LapMetrics) DrivingStyle {
+	style := DrivingStyle{DriverID: driverID, Laps: laps}
+	sort.Slice(style.Laps, func(i, j int) bool { return style.Laps[i].LapNumber < style.Laps[j].LapNumber })
+	if len(laps) == 0 {
+		style.Laps = []LapMetrics{}
+		return style
+	}
+
+	activations := 0
+	for _, lap := range laps {
+		style.AverageFullThrottle += lap.FullThrottle
+		style.AverageCoastingTime += lap.CoastingTime
+		style.AverageBrakingTime += lap.BrakingTime
+		for _, drs := range lap.DRS {
+			style.AverageDRSGain += drs.SpeedGained
+			activations++
+		}
+	}
+	n := float64(len(laps))
+	style.AverageFullThrottle /= n
+	style.AverageCoastingTime /= n
+	style.AverageBrakingTime /= n
+	if activations > 0 {
+		style.AverageDRSGain /= float64(activations)
+	}
+	return style
+}
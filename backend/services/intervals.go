@@ -0,0 +1,140 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/f1-analytics/models"
+)
+
+// GapPoint is a driver's gaps at one moment of a race. Lapped cars carry a lap
+// count instead of a time.
+type GapPoint struct {
+	Date             time.Time `json:"date"`
+	GapToLeader      float64   `json:"gap_to_leader"`
+	LapsBehindLeader int       `json:"laps_behind_leader,omitempty"`
+	Interval         float64   `json:"interval"`
+	LapsBehindAhead  int       `json:"laps_behind_ahead,omitempty"`
+}
+
+// LapGap is a driver's gaps as they completed a lap
+type LapGap struct {
+	Lap int `json:"lap"`
+	GapPoint
+}
+
+// DriverGaps is a driver's gap history over a race
+type DriverGaps struct {
+	DriverID uint       `json:"driver_id"`
+	Timeline []GapPoint `json:"timeline,omitempty"`
+	Laps     []LapGap   `json:"laps"`
+}
+
+// GapSeries builds every driver's gap timeline from the interval samples and
+// resamples it to the last sample published by the end of each of their laps.
+// Drivers whose laps have no end times are resampled at the end of each of the
+// leader's laps instead. The raw timeline can be left out to keep the response
+// small, unless no lap ends are known at all, when it is all there is.
+func GapSeries(samples []models.IntervalSample, laps []models.Lap, includeTimeline bool) []DriverGaps {
+	byDriver := make(map[uint][]GapPoint)
+	for _, sample := range samples {
+		byDriver[sample.DriverID] = append(byDriver[sample.DriverID], GapPoint{
+			Date:             sample.Date,
+			GapToLeader:      sample.GapToLeader,
+			LapsBehindLeader: sample.LapsBehindLeader,
+			Interval:         sample.Interval,
+			LapsBehindAhead:  sample.LapsBehindAhead,
+		})
+	}
+	windows := raceLapWindows(laps)
+	leaderWindows := leaderLapWindows(windows)
+
+	series := make([]DriverGaps, 0, len(byDriver))
+	for driverID, points := range byDriver {
+		sort.Slice(points, func(i, j int) bool { return points[i].Date.Before(points[j].Date) })
+
+		gaps := DriverGaps{DriverID: driverID, Laps: []LapGap{}}
+		if includeTimeline || len(leaderWindows) == 0 {
+			gaps.Timeline = points
+		}
+
+		driverWindows := windows[driverID]
+		if !hasLapEnds(driverWindows) {
+			driverWindows = leaderWindows
+		}
+		next := -1
+		for _, w := range driverWindows {
+			if w.end.IsZero() {
+				continue
+			}
+			for next+1 < len(points) && !points[next+1].Date.After(w.end) {
+				next++
+			}
+			if next >= 0 {
+				gaps.Laps = append(gaps.Laps, LapGap{Lap: w.number, GapPoint: points[next]})
+			}
+		}
+		series = append(series, gaps)
+	}
+
+	// Ordered as at the flag: most laps run, then fewest laps down, then gap
+	final := func(gaps DriverGaps) (int, float64) {
+		if len(gaps.Laps) > 0 {
+			last := gaps.Laps[len(gaps.Laps)-1]
+			return last.LapsBehindLeader, last.GapToLeader
+		}
+		if len(gaps.Timeline) > 0 {
+			last := gaps.Timeline[len(gaps.Timeline)-1]
+			return last.LapsBehindLeader, last.GapToLeader
+		}
+		return 0, 0
+	}
+	sort.Slice(series, func(i, j int) bool {
+		lapsI, gapI := final(series[i])
+		lapsJ, gapJ := final(series[j])
+		if len(series[i].Laps) != len(series[j].Laps) {
+			return len(series[i].Laps) > len(series[j].Laps)
+		}
+		if lapsI != lapsJ {
+			return lapsI < lapsJ
+		}
+		if gapI != gapJ {
+			return gapI < gapJ
+		}
+		return series[i].DriverID < series[j].DriverID
+	})
+	return series
+}
+
+// hasLapEnds reports whether any of a driver's laps has a known end
+func hasLapEnds(windows []lapWindow) bool {
+	for _, w := range windows {
+		if !w.end.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+// leaderLapWindows returns, for every lap, the window of the first car to
+// complete it, in lap order
+func leaderLapWindows(windows map[uint][]lapWindow) []lapWindow {
+	first := make(map[int]lapWindow)
+	for _, driverWindows := range windows {
+		for _, w := range driverWindows {
+			if w.end.IsZero() {
+				continue
+			}
+			if leader, ok := first[w.number]; !ok || w.end.Before(leader.end) {
+				first[w.number] = w
+			}
+		}
+	}
+
+	leader := make([]lapWindow, 0, len(first))
+	for _, w := range first {
+		leader = append(leader, w)
+	}
+	sort.Slice(leader, func(i, j int) bool { return leader[i].number < leader[j].number })
+	return leader
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/f1-analytics/models"
+)
+
+// LapChartDriver is one driver's line on a lap chart
+type LapChartDriver struct {
+	DriverID   uint   `json:"driver_id"`
+	Grid       int    `json:"grid"`
+	Positions  []int  `json:"positions"` // Running position at the end of each lap, lap 1 first; zero once out of the race
+	PitLaps    []int  `json:"pit_laps"`  // Laps at the end of which the driver pitted
+	RetiredLap int    `json:"retired_lap,omitempty"`
+	Position   int    `json:"position"` // Final classification
+	Status     string `json:"status"`
+}
+
+// LapChart is the running order of a race lap by lap
+type LapChart struct {
+	Laps        int                 `json:"laps"`
+	Drivers     []LapChartDriver    `json:"drivers"`
+	Neutralised []NeutralisedPeriod `json:"neutralised"`
+}
+
+// BuildLapChart lays out every driver's running position at the end of each
+// lap, with their stops and retirement. The recorded positions are used when
+// the race has them; otherwise the order is worked out from elapsed times.
+func BuildLapChart(laps []models.Lap, results []models.RaceDriver, periods []NeutralisedPeriod) LapChart {
+	chart := LapChart{Neutralised: periods}
+	if chart.Neutralised == nil {
+		chart.Neutralised = []NeutralisedPeriod{}
+	}
+
+	byDriver := make(map[uint][]models.Lap)
+	recorded := false
+	for _, lap := range laps {
+		byDriver[lap.DriverID] = append(byDriver[lap.DriverID], lap)
+		chart.Laps = max(chart.Laps, lap.LapNumber)
+		if lap.Position > 0 {
+			recorded = true
+		}
+	}
+
+	positions := make(map[uint]map[int]int, len(byDriver))
+	if recorded {
+		for _, lap := range laps {
+			if lap.Position == 0 {
+				continue
+			}
+			if positions[lap.DriverID] == nil {
+				positions[lap.DriverID] = make(map[int]int)
+			}
+			positions[lap.DriverID][lap.LapNumber] = lap.Position
+		}
+	} else {
+		positions = positionsFromElapsed(laps)
+	}
+
+	resultsByDriver := make(map[uint]models.RaceDriver, len(results))
+	for _, result := range results {
+		resultsByDriver[result.DriverID] = result
+	}
+
+	for driverID, driverLaps := range byDriver {
+		sort.Slice(driverLaps, func(i, j int) bool { return driverLaps[i].LapNumber < driverLaps[j].LapNumber })
+
+		result := resultsByDriver[driverID]
+		line := LapChartDriver{
+			DriverID:  driverID,
+			Grid:      result.Grid,
+			Positions: make([]int, chart.Laps),
+			PitLaps:   []int{},
+			Position:  result.Position,
+			Status:    result.Status,
+		}
+		for _, lap := range driverLaps {
+			line.Positions[lap.LapNumber-1] = positions[driverID][lap.LapNumber]
+			if lap.PitStop {
+				line.PitLaps = append(line.PitLaps, lap.LapNumber)
+			}
+		}
+
+		// Without a stored result, falling short of the leader's laps counts as a retirement
+		lastLap := driverLaps[len(driverLaps)-1].LapNumber
+		finished := ClassifyStatus(result.Status).Finished()
+		if result.DriverID == 0 {
+			finished = lastLap >= chart.Laps
+		}
+		if !finished {
+			line.RetiredLap = lastLap
+		}
+		chart.Drivers = append(chart.Drivers, line)
+	}
+
+	// Classified drivers first, then the rest by how far they got
+	sort.Slice(chart.Drivers, func(i, j int) bool {
+		a, b := chart.Drivers[i], chart.Drivers[j]
+		if (a.Position == 0) != (b.Position == 0) {
+			return a.Position != 0
+		}
+		if a.Position != b.Position {
+			return a.Position < b.Position
+		}
+		if a.RetiredLap != b.RetiredLap {
+			return a.RetiredLap > b.RetiredLap
+		}
+		return a.DriverID < b.DriverID
+	})
+	return chart
+}
+
+// positionsFromElapsed ranks the cars that completed each lap by their total
+// race time up to it
+func positionsFromElapsed(laps []models.Lap) map[uint]map[int]int {
+	timeline := buildTimeline(laps)
+
+	byLap := make(map[int][]uint)
+	for driverID, elapsed := range timeline.elapsed {
+		for lap := range elapsed {
+			byLap[lap] = append(byLap[lap], driverID)
+		}
+	}
+
+	positions := make(map[uint]map[int]int, len(timeline.elapsed))
+	for lap, drivers := range byLap {
+		sort.Slice(drivers, func(i, j int) bool {
+			ti, tj := timeline.elapsed[drivers[i]][lap], timeline.elapsed[drivers[j]][lap]
+			if ti != tj {
+				return ti < tj
+			}
+			return drivers[i] < drivers[j]
+		})
+		for i, driverID := range drivers {
+			if positions[driverID] == nil {
+				positions[driverID] = make(map[int]int)
+			}
+			positions[driverID][lap] = i + 1
+		}
+	}
+	return positions
+}
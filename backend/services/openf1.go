@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
 	"strings"
 	"sync"
 	"time"
@@ -15,6 +16,9 @@ const (
 	OpenF1BaseURL  = "https://api.openf1.org/v1"
 	RateLimitDelay = 5 * time.Second // 5 second delay between requests
 	MaxRetries     = 3               // Maximum number of retries for rate-limited requests
+
+	// OpenF1FirstSeason is the first season OpenF1 publishes data for
+	OpenF1FirstSeason = 2023
 )
 
 type OpenF1Service struct {
@@ -24,10 +28,11 @@ type OpenF1Service struct {
 	requestChan chan struct{}
 	cache       struct {
 		sync.RWMutex
-		drivers map[string][]Driver
-		teams   map[string][]Team
-		races   map[string][]Race
-		session *Session
+		drivers  map[string][]Driver
+		teams    map[string][]Team
+		races    map[string][]Race
+		sessions map[int][]Session // By meeting key
+		session  *Session
 	}
 }
 
@@ -41,6 +46,7 @@ func NewOpenF1Service() *OpenF1Service {
 	service.cache.drivers = make(map[string][]Driver)
 	service.cache.teams = make(map[string][]Team)
 	service.cache.races = make(map[string][]Race)
+	service.cache.sessions = make(map[int][]Session)
 	return service
 }
 
@@ -69,13 +75,15 @@ func GetCurrentSeason() int {
 
 // Session represents a Formula 1 session (practice, qualifying, race)
 type Session struct {
-	SessionKey  int       `json:"session_key"`
-	MeetingKey  int       `json:"meeting_key"`
-	SessionName string    `json:"session_name"`
-	CountryName string    `json:"country_name"`
-	Year        int       `json:"year"`
-	DateStart   time.Time `json:"date_start"`
-	DateEnd     time.Time `json:"date_end"`
+	SessionKey       int       `json:"session_key"`
+	MeetingKey       int       `json:"meeting_key"`
+	SessionName      string    `json:"session_name"`
+	SessionType      string    `json:"session_type"`
+	CountryName      string    `json:"country_name"`
+	CircuitShortName string    `json:"circuit_short_name"`
+	Year             int       `json:"year"`
+	DateStart        time.Time `json:"date_start"`
+	DateEnd          time.Time `json:"date_end"`
 }
 
 // GetCurrentSession fetches the current or most recent F1 session
@@ -381,6 +389,192 @@ func (s *OpenF1Service) GetRaceResults(raceID string) ([]RaceResult, error) {
 	return results, nil
 }
 
+// GetLaps fetches every lap of a session
+func (s *OpenF1Service) GetLaps(sessionKey int) ([]Lap, error) {
+	url := fmt.Sprintf("%s/laps?session_key=%d", OpenF1BaseURL, sessionKey)
+	resp, err := s.makeRequest(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch laps: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var laps []Lap
+	if err := json.NewDecoder(resp.Body).Decode(&laps); err != nil {
+		return nil, fmt.Errorf("failed to decode laps: %w", err)
+	}
+
+	return laps, nil
+}
+
+// GetSessions fetches every session of a meeting
+func (s *OpenF1Service) GetSessions(meetingKey int) ([]Session, error) {
+	// Check cache first
+	s.cache.RLock()
+	if sessions, ok := s.cache.sessions[meetingKey]; ok {
+		s.cache.RUnlock()
+		return sessions, nil
+	}
+	s.cache.RUnlock()
+
+	url := fmt.Sprintf("%s/sessions?meeting_key=%d", OpenF1BaseURL, meetingKey)
+	resp, err := s.makeRequest(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var sessions []Session
+	if err := json.NewDecoder(resp.Body).Decode(&sessions); err != nil {
+		return nil, fmt.Errorf("failed to decode sessions: %w", err)
+	}
+
+	// Cache the result
+	s.cache.Lock()
+	s.cache.sessions[meetingKey] = sessions
+	s.cache.Unlock()
+
+	return sessions, nil
+}
+
+// GetSeasonSessions fetches every session of a season with the given name, e.g. Race
+func (s *OpenF1Service) GetSeasonSessions(year int, sessionName string) ([]Session, error) {
+	url := fmt.Sprintf("%s/sessions?year=%d&session_name=%s", OpenF1BaseURL, year, neturl.QueryEscape(sessionName))
+	resp, err := s.makeRequest(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var sessions []Session
+	if err := json.NewDecoder(resp.Body).Decode(&sessions); err != nil {
+		return nil, fmt.Errorf("failed to decode sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// GetSessionResults fetches the classification of a session
+func (s *OpenF1Service) GetSessionResults(sessionKey int) ([]SessionResult, error) {
+	url := fmt.Sprintf("%s/session_result?session_key=%d", OpenF1BaseURL, sessionKey)
+	resp, err := s.makeRequest(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch session results: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var results []SessionResult
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("failed to decode session results: %w", err)
+	}
+
+	return results, nil
+}
+
+// GetStartingGrid fetches the starting grid of a session
+func (s *OpenF1Service) GetStartingGrid(sessionKey int) ([]GridPosition, error) {
+	url := fmt.Sprintf("%s/starting_grid?session_key=%d", OpenF1BaseURL, sessionKey)
+	resp, err := s.makeRequest(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch starting grid: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var grid []GridPosition
+	if err := json.NewDecoder(resp.Body).Decode(&grid); err != nil {
+		return nil, fmt.Errorf("failed to decode starting grid: %w", err)
+	}
+
+	return grid, nil
+}
+
+// GetStints fetches the tyre stints of a session
+func (s *OpenF1Service) GetStints(sessionKey int) ([]Stint, error) {
+	url := fmt.Sprintf("%s/stints?session_key=%d", OpenF1BaseURL, sessionKey)
+	resp, err := s.makeRequest(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch stints: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var stints []Stint
+	if err := json.NewDecoder(resp.Body).Decode(&stints); err != nil {
+		return nil, fmt.Errorf("failed to decode stints: %w", err)
+	}
+
+	return stints, nil
+}
+
+// GetPositions fetches every change of running position in a session
+func (s *OpenF1Service) GetPositions(sessionKey int) ([]Position, error) {
+	url := fmt.Sprintf("%s/position?session_key=%d", OpenF1BaseURL, sessionKey)
+	resp, err := s.makeRequest(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch positions: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var positions []Position
+	if err := json.NewDecoder(resp.Body).Decode(&positions); err != nil {
+		return nil, fmt.Errorf("failed to decode positions: %w", err)
+	}
+
+	return positions, nil
+}
+
+// GetIntervals fetches every driver's gaps to the leader and the car ahead
+// during a race
+func (s *OpenF1Service) GetIntervals(sessionKey int) ([]Interval, error) {
+	url := fmt.Sprintf("%s/intervals?session_key=%d", OpenF1BaseURL, sessionKey)
+	resp, err := s.makeRequest(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch intervals: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var intervals []Interval
+	if err := json.NewDecoder(resp.Body).Decode(&intervals); err != nil {
+		return nil, fmt.Errorf("failed to decode intervals: %w", err)
+	}
+
+	return intervals, nil
+}
+
+// GetRaceControl fetches every race control message of a session
+func (s *OpenF1Service) GetRaceControl(sessionKey int) ([]RaceControl, error) {
+	url := fmt.Sprintf("%s/race_control?session_key=%d", OpenF1BaseURL, sessionKey)
+	resp, err := s.makeRequest(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch race control messages: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var messages []RaceControl
+	if err := json.NewDecoder(resp.Body).Decode(&messages); err != nil {
+		return nil, fmt.Errorf("failed to decode race control messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// GetCarData fetches a driver's car telemetry between two moments of a session
+func (s *OpenF1Service) GetCarData(sessionKey int, driverNumber int, from, to time.Time) ([]CarData, error) {
+	const layout = "2006-01-02T15:04:05.000"
+	url := fmt.Sprintf("%s/car_data?session_key=%d&driver_number=%d&date>=%s&date<=%s",
+		OpenF1BaseURL, sessionKey, driverNumber, from.UTC().Format(layout), to.UTC().Format(layout))
+	resp, err := s.makeRequest(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch car data: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var samples []CarData
+	if err := json.NewDecoder(resp.Body).Decode(&samples); err != nil {
+		return nil, fmt.Errorf("failed to decode car data: %w", err)
+	}
+
+	return samples, nil
+}
+
 // Data structures matching OpenF1 API response
 type Team struct {
 	ID   int    `json:"id"`
@@ -413,6 +607,235 @@ type RaceResult struct {
 	Points       float64 `json:"points"`
 }
 
+// Lap is a single lap as published by OpenF1. Durations are in seconds and
+// speeds in km/h; fields OpenF1 leaves null decode as zero.
+type Lap struct {
+	SessionKey      int       `json:"session_key"`
+	MeetingKey      int       `json:"meeting_key"`
+	DriverNumber    int       `json:"driver_number"`
+	LapNumber       int       `json:"lap_number"`
+	DateStart       time.Time `json:"date_start"`
+	LapDuration     float64   `json:"lap_duration"`
+	DurationSector1 float64   `json:"duration_sector_1"`
+	DurationSector2 float64   `json:"duration_sector_2"`
+	DurationSector3 float64   `json:"duration_sector_3"`
+	I1Speed         int       `json:"i1_speed"`
+	I2Speed         int       `json:"i2_speed"`
+	StSpeed         int       `json:"st_speed"`
+	IsPitOutLap     bool      `json:"is_pit_out_lap"`
+}
+
+// Stint is a run on one set of tyres as published by OpenF1
+type Stint struct {
+	SessionKey     int    `json:"session_key"`
+	MeetingKey     int    `json:"meeting_key"`
+	DriverNumber   int    `json:"driver_number"`
+	StintNumber    int    `json:"stint_number"`
+	Compound       string `json:"compound"`
+	LapStart       int    `json:"lap_start"`
+	LapEnd         int    `json:"lap_end"`
+	TyreAgeAtStart int    `json:"tyre_age_at_start"`
+}
+
+// Position is a driver's running position from a moment of a session onwards,
+// as published by OpenF1
+type Position struct {
+	SessionKey   int       `json:"session_key"`
+	MeetingKey   int       `json:"meeting_key"`
+	DriverNumber int       `json:"driver_number"`
+	Date         time.Time `json:"date"`
+	Position     int       `json:"position"`
+}
+
+// Interval is a driver's gaps to the leader and the car ahead at a moment of a
+// race, as published by OpenF1
+type Interval struct {
+	SessionKey   int       `json:"session_key"`
+	MeetingKey   int       `json:"meeting_key"`
+	DriverNumber int       `json:"driver_number"`
+	Date         time.Time `json:"date"`
+	GapToLeader  Gap       `json:"gap_to_leader"`
+	Interval     Gap       `json:"interval"`
+}
+
+// Gap is an OpenF1 time gap, published as seconds, as a string such as
+// "+1 LAP" once a car is lapped, or as null when unknown
+type Gap struct {
+	Seconds float64
+	Laps    int
+	Valid   bool
+}
+
+// UnmarshalJSON decodes any of the forms OpenF1 publishes a gap in
+func (g *Gap) UnmarshalJSON(data []byte) error {
+	*g = Gap{}
+	if string(data) == "null" {
+		return nil
+	}
+
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err == nil {
+		*g = Gap{Seconds: seconds, Valid: true}
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("invalid gap %s: %w", data, err)
+	}
+	text = strings.TrimSpace(strings.TrimPrefix(text, "+"))
+	if text == "" {
+		return nil
+	}
+	var laps int
+	if _, err := fmt.Sscanf(text, "%d LAP", &laps); err == nil {
+		*g = Gap{Laps: laps, Valid: true}
+		return nil
+	}
+	if _, err := fmt.Sscanf(text, "%g", &seconds); err == nil {
+		*g = Gap{Seconds: seconds, Valid: true}
+		return nil
+	}
+	return fmt.Errorf("invalid gap %q", text)
+}
+
+// SessionResult is a driver's classification in a session as published by
+// OpenF1. Qualifying publishes a duration and gap for each part, other
+// sessions a single one; the position is null for unclassified drivers.
+type SessionResult struct {
+	SessionKey   int       `json:"session_key"`
+	MeetingKey   int       `json:"meeting_key"`
+	DriverNumber int       `json:"driver_number"`
+	Position     int       `json:"position"`
+	NumberOfLaps int       `json:"number_of_laps"`
+	Points       float64   `json:"points"`
+	DNF          bool      `json:"dnf"`
+	DNS          bool      `json:"dns"`
+	DSQ          bool      `json:"dsq"`
+	Duration     Durations `json:"duration"`
+	GapToLeader  Gaps      `json:"gap_to_leader"`
+}
+
+// Status describes how the driver's session ended, worded as stored results are
+func (r SessionResult) Status() string {
+	switch {
+	case r.DSQ:
+		return "Disqualified"
+	case r.DNS:
+		return "Did not start"
+	case r.DNF:
+		return "Retired"
+	}
+	if len(r.GapToLeader) > 0 {
+		switch laps := r.GapToLeader[0].Laps; {
+		case laps == 1:
+			return "+1 Lap"
+		case laps > 1:
+			return fmt.Sprintf("+%d Laps", laps)
+		}
+	}
+	return "Finished"
+}
+
+// GridPosition is a driver's starting grid slot as published by OpenF1
+type GridPosition struct {
+	SessionKey   int     `json:"session_key"`
+	MeetingKey   int     `json:"meeting_key"`
+	DriverNumber int     `json:"driver_number"`
+	Position     int     `json:"position"`
+	LapDuration  float64 `json:"lap_duration"`
+}
+
+// Durations are OpenF1 durations in seconds, published as a single value or
+// as one per qualifying part. Null values decode as zero.
+type Durations []float64
+
+// UnmarshalJSON decodes a single duration or a list of them
+func (d *Durations) UnmarshalJSON(data []byte) error {
+	*d = nil
+	if string(data) == "null" {
+		return nil
+	}
+
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err == nil {
+		*d = Durations{seconds}
+		return nil
+	}
+
+	var parts []*float64
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return fmt.Errorf("invalid duration %s: %w", data, err)
+	}
+	*d = make(Durations, len(parts))
+	for i, part := range parts {
+		if part != nil {
+			(*d)[i] = *part
+		}
+	}
+	return nil
+}
+
+// At returns the i-th duration, zero when it was not published
+func (d Durations) At(i int) float64 {
+	if i < 0 || i >= len(d) {
+		return 0
+	}
+	return d[i]
+}
+
+// Gaps are OpenF1 gaps, published as a single gap or as one per qualifying part
+type Gaps []Gap
+
+// UnmarshalJSON decodes a single gap or a list of them
+func (g *Gaps) UnmarshalJSON(data []byte) error {
+	*g = nil
+	if len(data) > 0 && data[0] == '[' {
+		var gaps []Gap
+		if err := json.Unmarshal(data, &gaps); err != nil {
+			return err
+		}
+		*g = gaps
+		return nil
+	}
+
+	var gap Gap
+	if err := json.Unmarshal(data, &gap); err != nil {
+		return err
+	}
+	*g = Gaps{gap}
+	return nil
+}
+
+// RaceControl is a race control message as published by OpenF1. Fields
+// OpenF1 leaves null decode as zero.
+type RaceControl struct {
+	SessionKey   int       `json:"session_key"`
+	MeetingKey   int       `json:"meeting_key"`
+	Date         time.Time `json:"date"`
+	DriverNumber int       `json:"driver_number"`
+	LapNumber    int       `json:"lap_number"`
+	Category     string    `json:"category"`
+	Flag         string    `json:"flag"`
+	Scope        string    `json:"scope"`
+	Sector       int       `json:"sector"`
+	Message      string    `json:"message"`
+}
+
+// CarData is one car telemetry sample as published by OpenF1
+type CarData struct {
+	SessionKey   int       `json:"session_key"`
+	MeetingKey   int       `json:"meeting_key"`
+	DriverNumber int       `json:"driver_number"`
+	Date         time.Time `json:"date"`
+	Speed        int       `json:"speed"`
+	RPM          int       `json:"rpm"`
+	Gear         int       `json:"n_gear"`
+	Throttle     int       `json:"throttle"`
+	Brake        int       `json:"brake"`
+	DRS          int       `json:"drs"`
+}
+
 // Helper functions for cache keys
 func getIntValue(i *int) int {
 	if i == nil {
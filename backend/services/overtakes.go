@@ -0,0 +1,382 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/f1-analytics/models"
+)
+
+// positionGroupWindow is how close together OpenF1 samples must be to belong
+// to the same reshuffle of the running order
+const positionGroupWindow = time.Second
+
+// Causes of a change in the running order
+const (
+	ChangeOvertake   = "overtake"   // Passed on track
+	ChangePit        = "pit"        // One of the pair was on an in-lap or out-lap
+	ChangeRetirement = "retirement" // The car passed had stopped
+	ChangePenalty    = "penalty"    // Reshuffled after the flag, as when time penalties are applied
+)
+
+// PositionChange is one car moving ahead of another in the running order
+type PositionChange struct {
+	RaceID       uint      `json:"race_id"`
+	Lap          int       `json:"lap"` // Lap of the car that moved ahead
+	Date         time.Time `json:"date"`
+	DriverID     uint      `json:"driver_id"` // The car that moved ahead
+	PassedID     uint      `json:"passed_id"`
+	FromPosition int       `json:"from_position"`
+	ToPosition   int       `json:"to_position"`
+	Cause        string    `json:"cause"`
+}
+
+// RaceOvertakes holds the running-order changes detected in one race
+type RaceOvertakes struct {
+	RaceID    uint             `json:"race_id"`
+	CircuitID uint             `json:"circuit_id"`
+	Laps      int              `json:"laps"`    // Racing laps, for rates per lap
+	Drivers   []uint           `json:"drivers"` // Every driver with position data
+	Changes   []PositionChange `json:"changes"`
+}
+
+// DriverOvertakes tallies a driver's on-track passes over a season
+type DriverOvertakes struct {
+	DriverID  uint    `json:"driver_id"`
+	Races     int     `json:"races"`
+	Overtakes int     `json:"overtakes"`
+	Overtaken int     `json:"overtaken"`
+	Net       int     `json:"net"`
+	PerRace   float64 `json:"per_race"`
+}
+
+// CircuitOvertaking measures how hard it is to pass at a circuit
+type CircuitOvertaking struct {
+	CircuitID       uint    `json:"circuit_id"`
+	Races           int     `json:"races"`
+	Overtakes       int     `json:"overtakes"`
+	PerRace         float64 `json:"per_race"`
+	PerLap          float64 `json:"per_lap"`
+	DifficultyIndex float64 `json:"difficulty_index"` // 0.5 for an average circuit, rising towards 1 the harder it is to pass
+}
+
+// lapWindow is the stretch of time a driver spent on one lap
+type lapWindow struct {
+	number     int
+	start, end time.Time // Zero when they cannot be worked out
+	pit        bool      // In-lap or out-lap
+	complete   bool
+}
+
+// raceLapWindows works out when every driver started and finished each lap.
+// Missing start times, as on the opening lap, are filled in from the lap
+// either side.
+func raceLapWindows(laps []models.Lap) map[uint][]lapWindow {
+	byDriver := make(map[uint][]models.Lap)
+	for _, lap := range laps {
+		byDriver[lap.DriverID] = append(byDriver[lap.DriverID], lap)
+	}
+
+	windows := make(map[uint][]lapWindow, len(byDriver))
+	for driverID, driverLaps := range byDriver {
+		sort.Slice(driverLaps, func(i, j int) bool { return driverLaps[i].LapNumber < driverLaps[j].LapNumber })
+
+		driverWindows := make([]lapWindow, len(driverLaps))
+		for i, lap := range driverLaps {
+			driverWindows[i] = lapWindow{
+				number:   lap.LapNumber,
+				start:    lap.DateStart,
+				pit:      lap.PitStop || lap.PitOutLap,
+				complete: lap.LapTime > 0,
+			}
+			if !lap.DateStart.IsZero() && lap.LapTime > 0 {
+				driverWindows[i].end = lap.DateStart.Add(lap.LapTime)
+			}
+		}
+		for i := range driverWindows {
+			w := &driverWindows[i]
+			if w.end.IsZero() && i+1 < len(driverWindows) {
+				w.end = driverWindows[i+1].start
+			}
+			if w.start.IsZero() && i > 0 {
+				w.start = driverWindows[i-1].end
+			}
+			if w.start.IsZero() && !w.end.IsZero() && driverLaps[i].LapTime > 0 {
+				w.start = w.end.Add(-driverLaps[i].LapTime)
+			}
+		}
+		windows[driverID] = driverWindows
+	}
+	return windows
+}
+
+// windowAt returns the lap a driver was on at a moment, falling back to the
+// first lap before any start time is known and the last lap after the end
+func windowAt(windows []lapWindow, t time.Time) (lapWindow, bool) {
+	if len(windows) == 0 {
+		return lapWindow{}, false
+	}
+	current := windows[0]
+	for _, w := range windows {
+		if w.start.IsZero() || w.start.After(t) {
+			continue
+		}
+		current = w
+	}
+	return current, true
+}
+
+// LapEndPositions works out every driver's running position at the end of
+// each lap from the position samples
+func LapEndPositions(samples []models.PositionSample, laps []models.Lap) map[uint]map[int]int {
+	byDriver := make(map[uint][]models.PositionSample)
+	for _, sample := range samples {
+		byDriver[sample.DriverID] = append(byDriver[sample.DriverID], sample)
+	}
+	for _, driverSamples := range byDriver {
+		sort.Slice(driverSamples, func(i, j int) bool { return driverSamples[i].Date.Before(driverSamples[j].Date) })
+	}
+
+	positions := make(map[uint]map[int]int)
+	for driverID, windows := range raceLapWindows(laps) {
+		driverSamples := byDriver[driverID]
+		if len(driverSamples) == 0 {
+			continue
+		}
+		positions[driverID] = make(map[int]int)
+		next := 0
+		position := 0
+		for _, w := range windows {
+			if w.end.IsZero() {
+				continue
+			}
+			for next < len(driverSamples) && !driverSamples[next].Date.After(w.end) {
+				position = driverSamples[next].Position
+				next++
+			}
+			if position > 0 {
+				positions[driverID][w.number] = position
+			}
+		}
+	}
+	return positions
+}
+
+// DetectPositionChanges replays the position samples of a race and records
+// every time one car moved ahead of another, attributing each change to an
+// overtake, a pit stop, a retirement or a post-race penalty
+func DetectPositionChanges(raceID uint, samples []models.PositionSample, laps []models.Lap) []PositionChange {
+	sorted := make([]models.PositionSample, len(samples))
+	copy(sorted, samples)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+
+	windows := raceLapWindows(laps)
+
+	// A driver who fell short of the leader's lap count stopped at the start
+	// of an unfinished lap, or otherwise at the end of the last one
+	leaderLaps := 0
+	var raceEnd time.Time
+	for _, driverWindows := range windows {
+		last := driverWindows[len(driverWindows)-1]
+		leaderLaps = max(leaderLaps, last.number)
+		if last.end.After(raceEnd) {
+			raceEnd = last.end
+		}
+	}
+	retiredAt := make(map[uint]time.Time)
+	for driverID, driverWindows := range windows {
+		last := driverWindows[len(driverWindows)-1]
+		if last.number >= leaderLaps {
+			continue
+		}
+		if last.complete {
+			retiredAt[driverID] = last.end
+		} else {
+			retiredAt[driverID] = last.start
+		}
+	}
+
+	inPit := func(driverID uint, t time.Time) bool {
+		w, ok := windowAt(windows[driverID], t)
+		return ok && w.pit
+	}
+	cause := func(driverID, passedID uint, t time.Time) string {
+		if !raceEnd.IsZero() && t.After(raceEnd) {
+			return ChangePenalty
+		}
+		if stopped, ok := retiredAt[passedID]; ok && !stopped.IsZero() && !t.Before(stopped) {
+			return ChangeRetirement
+		}
+		if inPit(driverID, t) || inPit(passedID, t) {
+			return ChangePit
+		}
+		return ChangeOvertake
+	}
+
+	order := make(map[uint]int)
+	var changes []PositionChange
+	for start := 0; start < len(sorted); {
+		end := start + 1
+		for end < len(sorted) && sorted[end].Date.Sub(sorted[start].Date) <= positionGroupWindow {
+			end++
+		}
+		group := sorted[start:end]
+		start = end
+
+		previous := make(map[uint]int, len(order))
+		for driverID, position := range order {
+			previous[driverID] = position
+		}
+		updated := make(map[uint]bool)
+		for _, sample := range group {
+			order[sample.DriverID] = sample.Position
+			updated[sample.DriverID] = true
+		}
+
+		// Only the cars that gained places record a change; every car that was
+		// ahead of them and no longer is was passed. A car whose own sample
+		// has not arrived yet still holds its old place.
+		for _, sample := range group {
+			driverID := sample.DriverID
+			from, seen := previous[driverID]
+			to := order[driverID]
+			if !seen || to >= from {
+				continue
+			}
+			for passedID, passedFrom := range previous {
+				if passedID == driverID || passedFrom >= from {
+					continue
+				}
+				if updated[passedID] && order[passedID] < to || !updated[passedID] && passedFrom < to {
+					continue
+				}
+				lap := 1
+				if w, ok := windowAt(windows[driverID], group[0].Date); ok {
+					lap = w.number
+				}
+				changes = append(changes, PositionChange{
+					RaceID:       raceID,
+					Lap:          lap,
+					Date:         sample.Date,
+					DriverID:     driverID,
+					PassedID:     passedID,
+					FromPosition: from,
+					ToPosition:   to,
+					Cause:        cause(driverID, passedID, sample.Date),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if !changes[i].Date.Equal(changes[j].Date) {
+			return changes[i].Date.Before(changes[j].Date)
+		}
+		if changes[i].DriverID != changes[j].DriverID {
+			return changes[i].DriverID < changes[j].DriverID
+		}
+		return changes[i].PassedID < changes[j].PassedID
+	})
+	return changes
+}
+
+// TallyOvertakes totals each driver's on-track passes made and suffered
+func TallyOvertakes(races []RaceOvertakes) []DriverOvertakes {
+	byDriver := make(map[uint]*DriverOvertakes)
+	driver := func(id uint) *DriverOvertakes {
+		entry, ok := byDriver[id]
+		if !ok {
+			entry = &DriverOvertakes{DriverID: id}
+			byDriver[id] = entry
+		}
+		return entry
+	}
+
+	for _, race := range races {
+		for _, driverID := range race.Drivers {
+			driver(driverID).Races++
+		}
+		for _, change := range race.Changes {
+			if change.Cause != ChangeOvertake {
+				continue
+			}
+			driver(change.DriverID).Overtakes++
+			driver(change.PassedID).Overtaken++
+		}
+	}
+
+	drivers := make([]DriverOvertakes, 0, len(byDriver))
+	for _, entry := range byDriver {
+		entry.Net = entry.Overtakes - entry.Overtaken
+		if entry.Races > 0 {
+			entry.PerRace = float64(entry.Overtakes) / float64(entry.Races)
+		}
+		drivers = append(drivers, *entry)
+	}
+	sort.Slice(drivers, func(i, j int) bool {
+		if drivers[i].Overtakes != drivers[j].Overtakes {
+			return drivers[i].Overtakes > drivers[j].Overtakes
+		}
+		return drivers[i].DriverID < drivers[j].DriverID
+	})
+	return drivers
+}
+
+// OvertakingDifficulty rates every circuit by its on-track passes per racing
+// lap against the average across all the races given. The index is
+// mean / (rate + mean): 0.5 for an average circuit, 1 where nobody passes.
+func OvertakingDifficulty(races []RaceOvertakes) []CircuitOvertaking {
+	type circuitTotals struct {
+		races, overtakes, laps int
+	}
+	byCircuit := make(map[uint]*circuitTotals)
+	totalOvertakes, totalLaps := 0, 0
+	for _, race := range races {
+		if race.Laps == 0 {
+			continue
+		}
+		totals, ok := byCircuit[race.CircuitID]
+		if !ok {
+			totals = &circuitTotals{}
+			byCircuit[race.CircuitID] = totals
+		}
+		overtakes := 0
+		for _, change := range race.Changes {
+			if change.Cause == ChangeOvertake {
+				overtakes++
+			}
+		}
+		totals.races++
+		totals.overtakes += overtakes
+		totals.laps += race.Laps
+		totalOvertakes += overtakes
+		totalLaps += race.Laps
+	}
+
+	mean := 0.0
+	if totalLaps > 0 {
+		mean = float64(totalOvertakes) / float64(totalLaps)
+	}
+
+	circuits := make([]CircuitOvertaking, 0, len(byCircuit))
+	for circuitID, totals := range byCircuit {
+		entry := CircuitOvertaking{
+			CircuitID: circuitID,
+			Races:     totals.races,
+			Overtakes: totals.overtakes,
+			PerRace:   float64(totals.overtakes) / float64(totals.races),
+			PerLap:    float64(totals.overtakes) / float64(totals.laps),
+		}
+		if mean > 0 {
+			entry.DifficultyIndex = mean / (entry.PerLap + mean)
+		}
+		circuits = append(circuits, entry)
+	}
+	sort.Slice(circuits, func(i, j int) bool {
+		if circuits[i].DifficultyIndex != circuits[j].DifficultyIndex {
+			return circuits[i].DifficultyIndex > circuits[j].DifficultyIndex
+		}
+		return circuits[i].CircuitID < circuits[j].CircuitID
+	})
+	return circuits
+}
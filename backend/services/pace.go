@@ -0,0 +1,193 @@
+package services
+
+import (
+	"math"
+	"sort"
+
+	"github.com/f1-analytics/models"
+)
+
+const (
+	// DefaultFuelKgPerLap is a typical race fuel burn
+	DefaultFuelKgPerLap = 1.6
+	// DefaultSecondsPerKg is the usual lap time cost of carrying fuel
+	DefaultSecondsPerKg = 0.03
+	// DefaultOutlierThreshold drops laps more than 7% slower than the driver's median
+	DefaultOutlierThreshold = 0.07
+)
+
+// FuelCorrection removes the lap time cost of the fuel still on board
+type FuelCorrection struct {
+	KgPerLap     float64 `json:"kg_per_lap"`
+	SecondsPerKg float64 `json:"seconds_per_kg"`
+	TotalLaps    int     `json:"total_laps"`
+}
+
+// Correct returns the lap time, in seconds, as if it had been run on an empty tank
+func (f FuelCorrection) Correct(lapNumber int, seconds float64) float64 {
+	remaining := float64(f.TotalLaps-lapNumber+1) * f.KgPerLap
+	if remaining < 0 {
+		remaining = 0
+	}
+	return seconds - remaining*f.SecondsPerKg
+}
+
+// PaceOptions tune which laps count as representative
+type PaceOptions struct {
+	Fuel             *FuelCorrection
+	OutlierThreshold float64 // Fraction above the driver's median beyond which a lap is dropped
+}
+
+// DriverPace is one driver's representative race pace, in seconds
+type DriverPace struct {
+	Rank         int     `json:"rank"`
+	DriverID     uint    `json:"driver_id"`
+	CleanLaps    int     `json:"clean_laps"`
+	ExcludedLaps int     `json:"excluded_laps"`
+	Median       float64 `json:"median"`
+	Mean         float64 `json:"mean"`
+	StdDev       float64 `json:"std_dev"`
+	Best         float64 `json:"best"`
+	Gap          float64 `json:"gap"` // To the fastest driver on the ranking measure
+}
+
+// PaceAnalysis ranks every driver by median and by mean representative lap
+type PaceAnalysis struct {
+	FuelCorrection *FuelCorrection `json:"fuel_correction,omitempty"`
+	ByMedian       []DriverPace    `json:"by_median"`
+	ByMean         []DriverPace    `json:"by_mean"`
+}
+
+// RepresentativeLap reports whether a lap was run at racing speed: not the
+// opening lap, not an in or out lap and not under a safety car of either kind
+func RepresentativeLap(lap models.Lap, previous *models.Lap) bool {
+	if lap.LapTime <= 0 || lap.LapNumber <= 1 || lap.PitStop || lap.PitOutLap {
+		return false
+	}
+	if lap.SafetyCar || lap.VirtualSafetyCar {
+		return false
+	}
+	// Out laps are not always flagged, so also drop the lap after a stop
+	if previous != nil && previous.LapNumber == lap.LapNumber-1 && previous.PitStop {
+		return false
+	}
+	return true
+}
+
+// RepresentativeLaps returns each driver's representative lap times in
+// seconds, fuel corrected when requested and with slow outliers removed
+func RepresentativeLaps(laps []models.Lap, opts PaceOptions) (map[uint][]float64, map[uint]int) {
+	byDriver := make(map[uint][]models.Lap)
+	for _, lap := range laps {
+		byDriver[lap.DriverID] = append(byDriver[lap.DriverID], lap)
+	}
+
+	threshold := opts.OutlierThreshold
+	if threshold <= 0 {
+		threshold = DefaultOutlierThreshold
+	}
+
+	clean := make(map[uint][]float64, len(byDriver))
+	excluded := make(map[uint]int, len(byDriver))
+	for driverID, driverLaps := range byDriver {
+		sort.Slice(driverLaps, func(i, j int) bool { return driverLaps[i].LapNumber < driverLaps[j].LapNumber })
+
+		var kept []models.Lap
+		for i, lap := range driverLaps {
+			var previous *models.Lap
+			if i > 0 {
+				previous = &driverLaps[i-1]
+			}
+			if RepresentativeLap(lap, previous) {
+				kept = append(kept, lap)
+			}
+		}
+
+		// Outliers are judged on raw times so fuel correction cannot hide them
+		raw := make([]float64, len(kept))
+		for i, lap := range kept {
+			raw[i] = lap.LapTime.Seconds()
+		}
+		limit := Median(raw) * (1 + threshold)
+
+		for _, lap := range kept {
+			seconds := lap.LapTime.Seconds()
+			if seconds > limit {
+				continue
+			}
+			if opts.Fuel != nil {
+				seconds = opts.Fuel.Correct(lap.LapNumber, seconds)
+			}
+			clean[driverID] = append(clean[driverID], seconds)
+		}
+		excluded[driverID] = len(driverLaps) - len(clean[driverID])
+	}
+
+	return clean, excluded
+}
+
+// AnalysePace computes every driver's representative race pace
+func AnalysePace(laps []models.Lap, opts PaceOptions) PaceAnalysis {
+	clean, excluded := RepresentativeLaps(laps, opts)
+
+	var pace []DriverPace
+	for driverID, times := range clean {
+		if len(times) == 0 {
+			continue
+		}
+		mean, stdDev := MeanStdDev(times)
+		best := times[0]
+		for _, t := range times {
+			best = math.Min(best, t)
+		}
+		pace = append(pace, DriverPace{
+			DriverID:     driverID,
+			CleanLaps:    len(times),
+			ExcludedLaps: excluded[driverID],
+			Median:       Median(times),
+			Mean:         mean,
+			StdDev:       stdDev,
+			Best:         best,
+		})
+	}
+
+	return PaceAnalysis{
+		FuelCorrection: opts.Fuel,
+		ByMedian:       rankPace(pace, func(p DriverPace) float64 { return p.Median }),
+		ByMean:         rankPace(pace, func(p DriverPace) float64 { return p.Mean }),
+	}
+}
+
+// rankPace orders drivers by the given measure and fills in rank and gap
+func rankPace(pace []DriverPace, measure func(DriverPace) float64) []DriverPace {
+	ranked := append([]DriverPace(nil), pace...)
+	sort.Slice(ranked, func(i, j int) bool {
+		if measure(ranked[i]) != measure(ranked[j]) {
+			return measure(ranked[i]) < measure(ranked[j])
+		}
+		return ranked[i].DriverID < ranked[j].DriverID
+	})
+	for i := range ranked {
+		ranked[i].Rank = i + 1
+		ranked[i].Gap = measure(ranked[i]) - measure(ranked[0])
+	}
+	return ranked
+}
+
+// MeanStdDev returns the mean and population standard deviation of the values
+func MeanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
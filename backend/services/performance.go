@@ -0,0 +1,193 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/f1-analytics/models"
+)
+
+// DefaultTrendWindow is how many rounds the performance trend averages over
+const DefaultTrendWindow = 3
+
+// PerformanceRound is what a round contributes to the team performance trend
+type PerformanceRound struct {
+	Round      int
+	RaceID     uint
+	TotalLaps  int
+	Qualifying []models.QualifyingResult
+	Laps       []models.Lap
+	TeamOf     func(driverID uint) uint
+}
+
+// TeamRoundPerformance is a team's pace at one round as percentage gaps to the
+// quickest. Gaps are null when the team set no time.
+type TeamRoundPerformance struct {
+	Round         int      `json:"round"`
+	RaceID        uint     `json:"race_id"`
+	QualiGap      *float64 `json:"quali_gap"`     // Best qualifying lap behind pole
+	RacePaceGap   *float64 `json:"race_pace_gap"` // Quicker car's median race lap behind the quickest team
+	QualiTrend    *float64 `json:"quali_trend"`   // Mean of the gaps over the trend window
+	RacePaceTrend *float64 `json:"race_pace_trend"`
+}
+
+// TeamPerformance is a team's performance trend over a season
+type TeamPerformance struct {
+	TeamID             uint                   `json:"team_id"`
+	Rounds             []TeamRoundPerformance `json:"rounds"`
+	AverageQualiGap    float64                `json:"average_quali_gap"`
+	AverageRacePaceGap float64                `json:"average_race_pace_gap"`
+}
+
+// TeamPerformanceTrend measures every team's qualifying and race pace gap to
+// the quickest at each round, and smooths both with a trailing mean over the
+// team's last window rounds with a time
+func TeamPerformanceTrend(rounds []PerformanceRound, opts PaceOptions, window int) []TeamPerformance {
+	if window <= 0 {
+		window = DefaultTrendWindow
+	}
+
+	byTeam := make(map[uint]*TeamPerformance)
+	team := func(id uint) *TeamPerformance {
+		entry, ok := byTeam[id]
+		if !ok {
+			entry = &TeamPerformance{TeamID: id}
+			byTeam[id] = entry
+		}
+		return entry
+	}
+
+	sorted := make([]PerformanceRound, len(rounds))
+	copy(sorted, rounds)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Round < sorted[j].Round })
+
+	for _, round := range sorted {
+		qualiGaps := qualifyingGaps(round)
+
+		roundOpts := opts
+		if opts.Fuel != nil {
+			fuel := *opts.Fuel
+			fuel.TotalLaps = round.TotalLaps
+			roundOpts.Fuel = &fuel
+		}
+		paceGaps := racePaceGaps(round, roundOpts)
+
+		teamIDs := make(map[uint]bool)
+		for teamID := range qualiGaps {
+			teamIDs[teamID] = true
+		}
+		for teamID := range paceGaps {
+			teamIDs[teamID] = true
+		}
+		for teamID := range teamIDs {
+			entry := TeamRoundPerformance{Round: round.Round, RaceID: round.RaceID}
+			if gap, ok := qualiGaps[teamID]; ok {
+				entry.QualiGap = &gap
+			}
+			if gap, ok := paceGaps[teamID]; ok {
+				entry.RacePaceGap = &gap
+			}
+			team(teamID).Rounds = append(team(teamID).Rounds, entry)
+		}
+	}
+
+	teams := make([]TeamPerformance, 0, len(byTeam))
+	for _, entry := range byTeam {
+		var qualiGaps, paceGaps []float64
+		for i := range entry.Rounds {
+			round := &entry.Rounds[i]
+			if round.QualiGap != nil {
+				qualiGaps = append(qualiGaps, *round.QualiGap)
+				trend := trailingMean(qualiGaps, window)
+				round.QualiTrend = &trend
+			}
+			if round.RacePaceGap != nil {
+				paceGaps = append(paceGaps, *round.RacePaceGap)
+				trend := trailingMean(paceGaps, window)
+				round.RacePaceTrend = &trend
+			}
+		}
+		if len(qualiGaps) > 0 {
+			entry.AverageQualiGap, _ = MeanStdDev(qualiGaps)
+		}
+		if len(paceGaps) > 0 {
+			entry.AverageRacePaceGap, _ = MeanStdDev(paceGaps)
+		}
+		teams = append(teams, *entry)
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		if teams[i].AverageQualiGap != teams[j].AverageQualiGap {
+			return teams[i].AverageQualiGap < teams[j].AverageQualiGap
+		}
+		return teams[i].TeamID < teams[j].TeamID
+	})
+	return teams
+}
+
+// qualifyingGaps returns each team's best qualifying lap behind pole, in percent
+func qualifyingGaps(round PerformanceRound) map[uint]float64 {
+	best := make(map[uint]float64)
+	pole := 0.0
+	for _, result := range round.Qualifying {
+		lap := result.BestLap().Seconds()
+		if lap <= 0 {
+			continue
+		}
+		teamID := result.TeamID
+		if teamID == 0 && round.TeamOf != nil {
+			teamID = round.TeamOf(result.DriverID)
+		}
+		if teamID == 0 {
+			continue
+		}
+		if current, ok := best[teamID]; !ok || lap < current {
+			best[teamID] = lap
+		}
+		if pole == 0 || lap < pole {
+			pole = lap
+		}
+	}
+
+	gaps := make(map[uint]float64, len(best))
+	for teamID, lap := range best {
+		gaps[teamID] = (lap - pole) / pole * 100
+	}
+	return gaps
+}
+
+// racePaceGaps returns each team's race pace behind the quickest team, in
+// percent, taking the quicker of its cars' median representative laps
+func racePaceGaps(round PerformanceRound, opts PaceOptions) map[uint]float64 {
+	clean, _ := RepresentativeLaps(round.Laps, opts)
+
+	best := make(map[uint]float64)
+	fastest := 0.0
+	for driverID, laps := range clean {
+		if len(laps) == 0 || round.TeamOf == nil {
+			continue
+		}
+		teamID := round.TeamOf(driverID)
+		if teamID == 0 {
+			continue
+		}
+		median := Median(laps)
+		if current, ok := best[teamID]; !ok || median < current {
+			best[teamID] = median
+		}
+		if fastest == 0 || median < fastest {
+			fastest = median
+		}
+	}
+
+	gaps := make(map[uint]float64, len(best))
+	for teamID, median := range best {
+		gaps[teamID] = (median - fastest) / fastest * 100
+	}
+	return gaps
+}
+
+// trailingMean averages the last window values
+func trailingMean(values []float64, window int) float64 {
+	start := max(0, len(values)-window)
+	mean, _ := MeanStdDev(values[start:])
+	return mean
+}
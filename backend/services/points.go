@@ -0,0 +1,232 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/f1-analytics/models"
+)
+
+// ShortenedRule describes how points are scaled when a race is stopped early
+type ShortenedRule string
+
+const (
+	// ShortenedHalf awards half points when less than 75% of the distance was run
+	ShortenedHalf ShortenedRule = "half"
+	// ShortenedScale2022 uses the graded scale introduced for 2022
+	ShortenedScale2022 ShortenedRule = "scale-2022"
+)
+
+// PointsSystem describes how championship points were awarded over a range of
+// seasons
+type PointsSystem struct {
+	Key           string        `json:"key"`
+	Name          string        `json:"name"`
+	FirstSeason   int           `json:"first_season"`
+	LastSeason    int           `json:"last_season"` // Zero while the system is still in use
+	Race          []float64     `json:"race"`        // Points by finishing position, the winner first
+	Sprint        []float64     `json:"sprint"`      // Empty when the system had no sprints
+	FastestLap    float64       `json:"fastest_lap"` // Bonus for the fastest lap, zero when not awarded
+	FastestLapTop int           `json:"fastest_lap_top"`
+	Shortened     ShortenedRule `json:"shortened"`
+}
+
+// Graded points for shortened races from 2022, keyed by the share of distance
+// completed
+var shortenedScale2022 = []struct {
+	below  float64
+	points []float64
+}{
+	{0.25, []float64{6, 4, 3, 2, 1}},
+	{0.50, []float64{13, 10, 8, 6, 5, 4, 3, 2, 1}},
+	{0.75, []float64{19, 14, 12, 9, 8, 6, 5, 3, 2, 1}},
+}
+
+// PointsSystems is the registry of historical points systems, oldest first.
+// Seasons before 1991 are not covered, as dropped scores are not modelled.
+var PointsSystems = []PointsSystem{
+	{
+		Key:         "1991",
+		Name:        "1991-2002: 10-6-4-3-2-1",
+		FirstSeason: 1991,
+		LastSeason:  2002,
+		Race:        []float64{10, 6, 4, 3, 2, 1},
+		Shortened:   ShortenedHalf,
+	},
+	{
+		Key:         "2003",
+		Name:        "2003-2009: top eight score",
+		FirstSeason: 2003,
+		LastSeason:  2009,
+		Race:        []float64{10, 8, 6, 5, 4, 3, 2, 1},
+		Shortened:   ShortenedHalf,
+	},
+	{
+		Key:         "2010",
+		Name:        "2010-2018: 25 points for a win",
+		FirstSeason: 2010,
+		LastSeason:  2018,
+		Race:        []float64{25, 18, 15, 12, 10, 8, 6, 4, 2, 1},
+		Shortened:   ShortenedHalf,
+	},
+	{
+		Key:           "2019",
+		Name:          "2019-2020: fastest lap bonus",
+		FirstSeason:   2019,
+		LastSeason:    2020,
+		Race:          []float64{25, 18, 15, 12, 10, 8, 6, 4, 2, 1},
+		FastestLap:    1,
+		FastestLapTop: 10,
+		Shortened:     ShortenedHalf,
+	},
+	{
+		Key:           "2021",
+		Name:          "2021: sprint qualifying",
+		FirstSeason:   2021,
+		LastSeason:    2021,
+		Race:          []float64{25, 18, 15, 12, 10, 8, 6, 4, 2, 1},
+		Sprint:        []float64{3, 2, 1},
+		FastestLap:    1,
+		FastestLapTop: 10,
+		Shortened:     ShortenedHalf,
+	},
+	{
+		Key:           "2022",
+		Name:          "2022-2024: top eight sprint, graded shortened races",
+		FirstSeason:   2022,
+		LastSeason:    2024,
+		Race:          []float64{25, 18, 15, 12, 10, 8, 6, 4, 2, 1},
+		Sprint:        []float64{8, 7, 6, 5, 4, 3, 2, 1},
+		FastestLap:    1,
+		FastestLapTop: 10,
+		Shortened:     ShortenedScale2022,
+	},
+	{
+		Key:         "2025",
+		Name:        "2025-: no fastest lap bonus",
+		FirstSeason: 2025,
+		Race:        []float64{25, 18, 15, 12, 10, 8, 6, 4, 2, 1},
+		Sprint:      []float64{8, 7, 6, 5, 4, 3, 2, 1},
+		Shortened:   ShortenedScale2022,
+	},
+}
+
+// PointsSystemForSeason returns the system a season was scored under, or an
+// error for seasons the registry does not cover
+func PointsSystemForSeason(season int) (PointsSystem, error) {
+	for _, system := range PointsSystems {
+		if season >= system.FirstSeason && (system.LastSeason == 0 || season <= system.LastSeason) {
+			return system, nil
+		}
+	}
+	return PointsSystem{}, fmt.Errorf("no points system recorded for season %d", season)
+}
+
+// LookupPointsSystem finds a system by key, or by any season it was used in,
+// so that "2008" resolves to the 2003-2009 system
+func LookupPointsSystem(name string) (PointsSystem, error) {
+	for _, system := range PointsSystems {
+		if system.Key == name {
+			return system, nil
+		}
+	}
+	if season, err := strconv.Atoi(name); err == nil {
+		return PointsSystemForSeason(season)
+	}
+	return PointsSystem{}, fmt.Errorf("unknown points system: %s", name)
+}
+
+// RacePoints returns the points for a Grand Prix finish. distance is the share
+// of the scheduled race distance that was completed.
+func (p PointsSystem) RacePoints(position int, fastestLap bool, distance float64) float64 {
+	table := p.Race
+	scale := 1.0
+	bonus := fastestLap && p.FastestLap > 0 && position >= 1 && (p.FastestLapTop == 0 || position <= p.FastestLapTop)
+
+	if distance > 0 && distance < 0.75 {
+		switch p.Shortened {
+		case ShortenedHalf:
+			scale = 0.5
+			bonus = false
+		case ShortenedScale2022:
+			for _, band := range shortenedScale2022 {
+				if distance < band.below {
+					table = band.points
+					break
+				}
+			}
+			if distance < 0.5 {
+				bonus = false
+			}
+		}
+	}
+
+	points := 0.0
+	if position >= 1 && position <= len(table) {
+		points = table[position-1] * scale
+	}
+	if bonus {
+		points += p.FastestLap
+	}
+	return points
+}
+
+// SprintPoints returns the points for a sprint finish
+func (p PointsSystem) SprintPoints(position int) float64 {
+	if position >= 1 && position <= len(p.Sprint) {
+		return p.Sprint[position-1]
+	}
+	return 0
+}
+
+// RaceDistance returns the share of the scheduled distance a race ran, or 1
+// when the race went the full distance or the lap counts are unknown
+func RaceDistance(race models.Race) float64 {
+	if race.Laps <= 0 || race.LapsCompleted <= 0 || race.LapsCompleted >= race.Laps {
+		return 1
+	}
+	return float64(race.LapsCompleted) / float64(race.Laps)
+}
+
+// FastestLapDriver returns the driver who set a race's fastest lap, classified
+// or not, or zero when no lap times were recorded
+func FastestLapDriver(results []models.RaceDriver) uint {
+	var fastestDriver uint
+	var fastest time.Duration
+	for _, result := range results {
+		if result.FastestLap > 0 && (fastest == 0 || result.FastestLap < fastest) {
+			fastest = result.FastestLap
+			fastestDriver = result.DriverID
+		}
+	}
+	return fastestDriver
+}
+
+// RescoreSeason returns a copy of the season with every race and sprint result
+// scored under the given points system, leaving the stored results untouched
+func RescoreSeason(season SeasonResults, system PointsSystem) SeasonResults {
+	rescored := SeasonResults{
+		Season:  season.Season,
+		Races:   season.Races,
+		Results: make(map[uint][]models.RaceDriver, len(season.Results)),
+	}
+
+	for _, race := range season.Races {
+		results := season.Results[race.ID]
+
+		// The bonus only counts for a classified finisher, so an unclassified
+		// driver's fastest lap scores for nobody
+		fastestDriver := FastestLapDriver(results)
+		distance := RaceDistance(race)
+		scored := make([]models.RaceDriver, len(results))
+		for i, result := range results {
+			result.Points = system.RacePoints(result.Position, result.DriverID == fastestDriver, distance)
+			result.SprintPoints = system.SprintPoints(result.SprintPosition)
+			scored[i] = result
+		}
+		rescored.Results[race.ID] = scored
+	}
+
+	return rescored
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/f1-analytics/models"
+)
+
+// TestRescoreShortenedRace scores the 2021 Belgian Grand Prix, classified
+// after 1 of its 44 scheduled laps, under the 2021 and 2022 systems
+func TestRescoreShortenedRace(t *testing.T) {
+	race := models.Race{Season: 2021, Round: 12, Laps: 44, LapsCompleted: 1}
+	race.ID = 12
+	season := SeasonResults{
+		Season: 2021,
+		Races:  []models.Race{race},
+		Results: map[uint][]models.RaceDriver{
+			12: {
+				{DriverID: 1, RaceID: 12, Position: 1},
+				{DriverID: 2, RaceID: 12, Position: 2},
+				{DriverID: 3, RaceID: 12, Position: 3},
+				{DriverID: 4, RaceID: 12, Position: 10},
+			},
+		},
+	}
+
+	tests := []struct {
+		system string
+		want   []float64
+	}{
+		{"2021", []float64{12.5, 9, 7.5, 0.5}},
+		{"2022", []float64{6, 4, 3, 0}},
+	}
+	for _, tt := range tests {
+		system, err := LookupPointsSystem(tt.system)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results := RescoreSeason(season, system).Results[race.ID]
+		for i, result := range results {
+			if result.Points != tt.want[i] {
+				t.Errorf("%s system: P%d scored %v, want %v", tt.system, result.Position, result.Points, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestRaceDistance(t *testing.T) {
+	tests := []struct {
+		name                string
+		laps, lapsCompleted int
+		want                float64
+	}{
+		{"full distance", 44, 44, 1},
+		{"shortened", 44, 11, 0.25},
+		{"scheduled laps unknown", 0, 11, 1},
+		{"results not in", 44, 0, 1},
+	}
+	for _, tt := range tests {
+		got := RaceDistance(models.Race{Laps: tt.laps, LapsCompleted: tt.lapsCompleted})
+		if got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRacePoints(t *testing.T) {
+	tests := []struct {
+		name       string
+		system     string
+		position   int
+		fastestLap bool
+		distance   float64
+		want       float64
+	}{
+		{"win with fastest lap", "2022", 1, true, 1, 26},
+		{"fastest lap outside the top ten", "2022", 11, true, 1, 0},
+		{"fastest lap unclassified", "2022", 0, true, 1, 0},
+		{"no fastest lap bonus", "2025", 1, true, 1, 25},
+		{"graded, over half distance keeps the bonus", "2022", 1, true, 0.6, 20},
+		{"graded, under half distance loses the bonus", "2022", 1, true, 0.3, 13},
+		{"graded, under a quarter pays the top five", "2022", 5, false, 0.2, 1},
+		{"graded, under a quarter", "2022", 6, false, 0.2, 0},
+		{"graded, full points from three quarters", "2022", 2, false, 0.75, 18},
+		{"half points without the bonus", "2021", 1, true, 0.6, 12.5},
+		{"full points from three quarters", "2021", 1, false, 0.8, 25},
+	}
+	for _, tt := range tests {
+		system, err := LookupPointsSystem(tt.system)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := system.RacePoints(tt.position, tt.fastestLap, tt.distance); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPointsSystemForSeason(t *testing.T) {
+	tests := []struct {
+		season  int
+		want    string
+		wantErr bool
+	}{
+		{1990, "", true},
+		{1991, "1991", false},
+		{2008, "2003", false},
+		{2021, "2021", false},
+		{2030, "2025", false},
+	}
+	for _, tt := range tests {
+		system, err := PointsSystemForSeason(tt.season)
+		if (err != nil) != tt.wantErr || system.Key != tt.want {
+			t.Errorf("PointsSystemForSeason(%d) = %q, %v; want %q", tt.season, system.Key, err, tt.want)
+		}
+	}
+}
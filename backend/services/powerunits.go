@@ -0,0 +1,39 @@
+package services
+
+import "strings"
+
+// powerUnitSuppliers lists engine suppliers from the season each deal began.
+// OpenF1 does not publish suppliers, so only the seasons it covers are listed.
+var powerUnitSuppliers = []struct {
+	teams    []string // Team names as OpenF1 publishes them, in lower case
+	from     int
+	supplier string
+}{
+	{[]string{"red bull racing", "alphatauri", "rb", "racing bulls"}, 2023, "Honda RBPT"},
+	{[]string{"red bull racing", "racing bulls"}, 2026, "Red Bull Ford"},
+	{[]string{"ferrari", "haas f1 team", "alfa romeo", "kick sauber", "sauber"}, 2023, "Ferrari"},
+	{[]string{"cadillac"}, 2026, "Ferrari"},
+	{[]string{"audi"}, 2026, "Audi"},
+	{[]string{"mercedes", "mclaren", "aston martin", "williams"}, 2023, "Mercedes"},
+	{[]string{"aston martin"}, 2026, "Honda"},
+	{[]string{"alpine"}, 2023, "Renault"},
+	{[]string{"alpine"}, 2026, "Mercedes"},
+}
+
+// PowerUnitSupplier returns the engine supplier a team raced with in a
+// season, or an empty string when it is not known
+func PowerUnitSupplier(teamName string, season int) string {
+	name := strings.ToLower(strings.TrimSpace(teamName))
+	supplier, from := "", 0
+	for _, deal := range powerUnitSuppliers {
+		if deal.from > season || deal.from < from {
+			continue
+		}
+		for _, team := range deal.teams {
+			if team == name {
+				supplier, from = deal.supplier, deal.from
+			}
+		}
+	}
+	return supplier
+}
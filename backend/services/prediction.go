@@ -0,0 +1,341 @@
+package services
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+
+	"github.com/f1-analytics/models"
+)
+
+const (
+	// PredictionModel names the current prediction model, so stored
+	// predictions from an older one can be told apart
+	PredictionModel = "grid-form-v2"
+	// PredictionRuns is how many times a race is simulated to spread a prediction over positions
+	PredictionRuns = 10000
+	// PredictionHistoryRaces is how many earlier races are looked at for form and team pace
+	PredictionHistoryRaces = 10
+	// PredictionCircuitVisits is how many earlier races at the circuit make up its history
+	PredictionCircuitVisits = 3
+	// predictionFormWindow is how many of a driver's latest races make up their form
+	predictionFormWindow = 5
+	// predictionPaceWindow is how many of the latest qualifying sessions make up a team's pace
+	predictionPaceWindow = 5
+	// predictionSpread is the spread, in positions, of a driver's simulated result around their score
+	predictionSpread = 3.0
+	// minProbability keeps a single impossible outcome from making log loss infinite
+	minProbability = 1e-4
+)
+
+// Weights of the inputs to a driver's score. Inputs a driver lacks are left
+// out and the rest reweighted.
+const (
+	gridWeight     = 0.45
+	formWeight     = 0.25
+	circuitWeight  = 0.1
+	teamPaceWeight = 0.2
+)
+
+// PredictionData is everything known about a race before it starts
+type PredictionData struct {
+	Race           models.Race
+	Entrants       []models.RaceDriver                // Driver, team and grid slot, the grid zero when not yet set
+	Recent         []models.Race                      // Earlier races, latest first
+	CircuitHistory []models.Race                      // Earlier races at the circuit, latest first
+	Results        map[uint][]models.RaceDriver       // Results of the recent and circuit races
+	Qualifying     map[uint][]models.QualifyingResult // Qualifying of the recent races
+}
+
+// PredictionFactors are a driver's inputs, each as the finishing position it
+// points to. Factors are null when there was nothing to go on.
+type PredictionFactors struct {
+	Grid     *float64 `json:"grid"`
+	Form     *float64 `json:"form"`      // Mean result over the driver's latest races, retirements counted last
+	Circuit  *float64 `json:"circuit"`   // Mean result at the circuit's latest races
+	TeamPace *float64 `json:"team_pace"` // Where the team's recent qualifying pace ranks it, as a car position
+}
+
+// DriverPrediction is a driver's predicted result
+type DriverPrediction struct {
+	DriverID          uint              `json:"driver_id"`
+	TeamID            uint              `json:"team_id"`
+	Grid              int               `json:"grid"`
+	Factors           PredictionFactors `json:"factors"`
+	Score             float64           `json:"score"` // Weighted mean of the factors
+	DNFProbability    float64           `json:"dnf_probability"`
+	ExpectedPosition  float64           `json:"expected_position"`
+	WinProbability    float64           `json:"win_probability"`
+	PodiumProbability float64           `json:"podium_probability"`
+	PointsProbability float64           `json:"points_probability"`
+	Positions         []float64         `json:"positions"` // Probability of each finishing position, the win first
+}
+
+// RacePrediction is the predicted result of a race
+type RacePrediction struct {
+	RaceID  uint               `json:"race_id"`
+	Model   string             `json:"model"`
+	Drivers []DriverPrediction `json:"drivers"`
+}
+
+// PredictionScore measures a prediction against the result. Lower is better
+// for all three.
+type PredictionScore struct {
+	RaceID   uint    `json:"race_id,omitempty"`
+	Drivers  int     `json:"drivers"`
+	Brier    float64 `json:"brier"`     // Squared error over every finishing position, per driver
+	LogLoss  float64 `json:"log_loss"`  // Negative log of the chance given to the actual position, per driver
+	WinBrier float64 `json:"win_brier"` // Squared error of the win probability, per driver
+}
+
+// PredictRace scores every entrant from their grid slot, form, circuit
+// history and team pace, then simulates the race PredictionRuns times: each
+// car retires with its team's recent DNF rate and the rest finish in the
+// order of their score plus noise. The simulation is seeded by the race, so
+// the same data always gives the same prediction.
+func PredictRace(data PredictionData) RacePrediction {
+	prediction := RacePrediction{RaceID: data.Race.ID, Model: PredictionModel}
+	n := len(data.Entrants)
+	if n == 0 {
+		prediction.Drivers = []DriverPrediction{}
+		return prediction
+	}
+
+	teamPace := teamPacePositions(data)
+	dnfRates := DNFRates(SeasonResults{Races: data.Recent, Results: data.Results})
+
+	drivers := make([]DriverPrediction, n)
+	for i, entrant := range data.Entrants {
+		driver := DriverPrediction{
+			DriverID:       entrant.DriverID,
+			TeamID:         entrant.TeamID,
+			Grid:           entrant.Grid,
+			DNFProbability: dnfPrior,
+			Positions:      make([]float64, n),
+		}
+		if entrant.Grid > 0 {
+			grid := float64(entrant.Grid)
+			driver.Factors.Grid = &grid
+		}
+		driver.Factors.Form = meanFinish(data.Recent, data.Results, entrant.DriverID, predictionFormWindow)
+		driver.Factors.Circuit = meanFinish(data.CircuitHistory, data.Results, entrant.DriverID, PredictionCircuitVisits)
+		if pace, ok := teamPace[entrant.TeamID]; ok {
+			driver.Factors.TeamPace = &pace
+		}
+		if rate, ok := dnfRates[entrant.TeamID]; ok {
+			driver.DNFProbability = rate
+		}
+
+		total, weights := 0.0, 0.0
+		for _, factor := range []struct {
+			value  *float64
+			weight float64
+		}{
+			{driver.Factors.Grid, gridWeight},
+			{driver.Factors.Form, formWeight},
+			{driver.Factors.Circuit, circuitWeight},
+			{driver.Factors.TeamPace, teamPaceWeight},
+		} {
+			if factor.value != nil {
+				total += *factor.value * factor.weight
+				weights += factor.weight
+			}
+		}
+		driver.Score = float64(n) * 0.75
+		if weights > 0 {
+			driver.Score = total / weights
+		}
+		drivers[i] = driver
+	}
+
+	// Points go as deep into the field as the season's points system pays,
+	// or the top ten for seasons the registry does not cover
+	pointsPaid := 10
+	if system, err := PointsSystemForSeason(data.Race.Season); err == nil {
+		pointsPaid = len(system.Race)
+	}
+
+	rng := rand.New(rand.NewPCG(uint64(data.Race.ID), uint64(n)))
+	counts := make([][]int, n)
+	for i := range counts {
+		counts[i] = make([]int, n)
+	}
+	draws := make([]float64, n)
+	order := make([]int, n)
+	for run := 0; run < PredictionRuns; run++ {
+		for i, driver := range drivers {
+			draws[i] = driver.Score + rng.NormFloat64()*predictionSpread
+			// Retired cars are classified behind every finisher
+			if rng.Float64() < driver.DNFProbability {
+				draws[i] += float64(n) * 10
+			}
+			order[i] = i
+		}
+		sort.Slice(order, func(a, b int) bool { return draws[order[a]] < draws[order[b]] })
+		for position, i := range order {
+			counts[i][position]++
+		}
+	}
+
+	for i := range drivers {
+		driver := &drivers[i]
+		for position, count := range counts[i] {
+			p := float64(count) / PredictionRuns
+			driver.Positions[position] = p
+			driver.ExpectedPosition += float64(position+1) * p
+			if position < 3 {
+				driver.PodiumProbability += p
+			}
+			if position < pointsPaid {
+				driver.PointsProbability += p
+			}
+		}
+		driver.WinProbability = driver.Positions[0]
+	}
+	sort.SliceStable(drivers, func(i, j int) bool { return drivers[i].ExpectedPosition < drivers[j].ExpectedPosition })
+	prediction.Drivers = drivers
+	return prediction
+}
+
+// meanFinish averages a driver's results over their latest races in the
+// list, up to window of them. Anything short of a classified finish counts as
+// last of the field.
+func meanFinish(races []models.Race, results map[uint][]models.RaceDriver, driverID uint, window int) *float64 {
+	var finishes []float64
+	for _, race := range races {
+		if len(finishes) == window {
+			break
+		}
+		for _, result := range results[race.ID] {
+			if result.DriverID != driverID || !ClassifyStatus(result.Status).Started() {
+				continue
+			}
+			if result.Position > 0 && ClassifyStatus(result.Status).Finished() {
+				finishes = append(finishes, float64(result.Position))
+			} else {
+				finishes = append(finishes, float64(len(results[race.ID])))
+			}
+		}
+	}
+	if len(finishes) == 0 {
+		return nil
+	}
+	mean, _ := MeanStdDev(finishes)
+	return &mean
+}
+
+// teamPacePositions ranks teams by their mean gap to pole over the latest
+// qualifying sessions and turns each rank into the position of the team's
+// average car, so the quickest team sits at 1.5
+func teamPacePositions(data PredictionData) map[uint]float64 {
+	gaps := make(map[uint][]float64)
+	sessions := 0
+	for _, race := range data.Recent {
+		if sessions == predictionPaceWindow {
+			break
+		}
+		qualifying := data.Qualifying[race.ID]
+		if len(qualifying) == 0 {
+			continue
+		}
+		sessions++
+		// Qualifying stored without a team falls back to the team raced for
+		results := data.Results[race.ID]
+		teamOf := func(driverID uint) uint {
+			for _, result := range results {
+				if result.DriverID == driverID {
+					return result.TeamID
+				}
+			}
+			return 0
+		}
+		for teamID, gap := range qualifyingGaps(PerformanceRound{RaceID: race.ID, Qualifying: qualifying, TeamOf: teamOf}) {
+			gaps[teamID] = append(gaps[teamID], gap)
+		}
+	}
+
+	type teamGap struct {
+		teamID uint
+		gap    float64
+	}
+	ranked := make([]teamGap, 0, len(gaps))
+	for teamID, teamGaps := range gaps {
+		mean, _ := MeanStdDev(teamGaps)
+		ranked = append(ranked, teamGap{teamID, mean})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].gap != ranked[j].gap {
+			return ranked[i].gap < ranked[j].gap
+		}
+		return ranked[i].teamID < ranked[j].teamID
+	})
+
+	positions := make(map[uint]float64, len(ranked))
+	for rank, team := range ranked {
+		positions[team.teamID] = float64(2*rank) + 1.5
+	}
+	return positions
+}
+
+// ScorePrediction measures a prediction against the race's results. Drivers
+// classified outside the predicted field count as finishing last of it.
+func ScorePrediction(prediction RacePrediction, results []models.RaceDriver) PredictionScore {
+	score := PredictionScore{RaceID: prediction.RaceID}
+	actual := make(map[uint]int, len(results))
+	for _, result := range results {
+		if ClassifyStatus(result.Status).Started() {
+			actual[result.DriverID] = result.Position
+		}
+	}
+
+	for _, driver := range prediction.Drivers {
+		position, ok := actual[driver.DriverID]
+		n := len(driver.Positions)
+		if !ok || n == 0 {
+			continue
+		}
+		if position <= 0 || position > n {
+			position = n
+		}
+
+		for i, p := range driver.Positions {
+			outcome := 0.0
+			if i+1 == position {
+				outcome = 1
+			}
+			score.Brier += (p - outcome) * (p - outcome)
+		}
+		score.LogLoss -= math.Log(max(driver.Positions[position-1], minProbability))
+		won := 0.0
+		if position == 1 {
+			won = 1
+		}
+		score.WinBrier += (driver.WinProbability - won) * (driver.WinProbability - won)
+		score.Drivers++
+	}
+
+	if score.Drivers > 0 {
+		score.Brier /= float64(score.Drivers)
+		score.LogLoss /= float64(score.Drivers)
+		score.WinBrier /= float64(score.Drivers)
+	}
+	return score
+}
+
+// CombineScores averages race scores, weighting each by its drivers
+func CombineScores(scores []PredictionScore) PredictionScore {
+	var combined PredictionScore
+	for _, score := range scores {
+		weight := float64(score.Drivers)
+		combined.Brier += score.Brier * weight
+		combined.LogLoss += score.LogLoss * weight
+		combined.WinBrier += score.WinBrier * weight
+		combined.Drivers += score.Drivers
+	}
+	if combined.Drivers > 0 {
+		combined.Brier /= float64(combined.Drivers)
+		combined.LogLoss /= float64(combined.Drivers)
+		combined.WinBrier /= float64(combined.Drivers)
+	}
+	return combined
+}
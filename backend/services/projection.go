@@ -0,0 +1,296 @@
+package services
+
+import (
+	"math/rand/v2"
+	"sort"
+
+	"github.com/f1-analytics/models"
+)
+
+const (
+	// DefaultProjectionRuns is how many times the rest of the season is simulated
+	DefaultProjectionRuns = 5000
+	// MaxProjectionRuns caps the runs a single request can ask for
+	MaxProjectionRuns = 50000
+	// MaxProjectionSeed keeps seeds exact as JSON numbers, which JavaScript reads as doubles
+	MaxProjectionSeed = 1<<53 - 1
+	// DefaultFormWindow is how many of a driver's latest finishes make up their form
+	DefaultFormWindow = 5
+	// minFormSpread keeps a driver's finishing spread from collapsing on a short run of identical results
+	minFormSpread = 1.5
+	// dnfPrior and dnfPriorWeight shrink a team's retirement rate towards a
+	// typical rate until it has enough starts of its own
+	dnfPrior       = 0.1
+	dnfPriorWeight = 10.0
+)
+
+// ProjectionOptions tune a season projection
+type ProjectionOptions struct {
+	Runs       int    `json:"runs"`
+	Seed       uint64 `json:"seed"`
+	FormWindow int    `json:"form_window"`
+}
+
+// ProjectedEntry is a driver's or constructor's simulated championship outcome
+type ProjectedEntry struct {
+	ID                  uint      `json:"id"`
+	CurrentPoints       float64   `json:"current_points"`
+	ExpectedPoints      float64   `json:"expected_points"`
+	ChampionProbability float64   `json:"champion_probability"`
+	Positions           []float64 `json:"positions"` // Probability of each final position, the title first
+}
+
+// DriverForm is what a driver's simulated finishes are drawn from
+type DriverForm struct {
+	DriverID     uint    `json:"driver_id"`
+	TeamID       uint    `json:"team_id"`
+	MeanPosition float64 `json:"mean_position"`
+	Spread       float64 `json:"spread"`
+	DNFRate      float64 `json:"dnf_rate"`
+}
+
+// Projection is the outcome of simulating the rest of a season
+type Projection struct {
+	Options         ProjectionOptions `json:"options"`
+	RemainingRounds int               `json:"remaining_rounds"`
+	Form            []DriverForm      `json:"form"`
+	Drivers         []ProjectedEntry  `json:"drivers"`
+	Constructors    []ProjectedEntry  `json:"constructors"`
+}
+
+// DNFRates returns each team's chance of a car retiring from a race, shrunk
+// towards a typical rate while the team has few starts
+func DNFRates(season SeasonResults) map[uint]float64 {
+	starts := make(map[uint]int)
+	retirements := make(map[uint]int)
+	for _, race := range season.Races {
+		for _, result := range season.Results[race.ID] {
+			status := ClassifyStatus(result.Status)
+			if result.TeamID == 0 || !status.Started() {
+				continue
+			}
+			starts[result.TeamID]++
+			if status.Retired() {
+				retirements[result.TeamID]++
+			}
+		}
+	}
+
+	rates := make(map[uint]float64, len(starts))
+	for teamID, n := range starts {
+		rates[teamID] = (float64(retirements[teamID]) + dnfPrior*dnfPriorWeight) / (float64(n) + dnfPriorWeight)
+	}
+	return rates
+}
+
+// CurrentForm works out every driver who raced in the latest round: the mean
+// and spread of their last window classified finishes, and their team's
+// retirement rate
+func CurrentForm(season SeasonResults, window int, dnfRates map[uint]float64) []DriverForm {
+	if window <= 0 {
+		window = DefaultFormWindow
+	}
+
+	last := season.LastRound()
+	var lastRace models.Race
+	for _, race := range season.Races {
+		if race.Round == last {
+			lastRace = race
+		}
+	}
+
+	fieldSize := len(season.Results[lastRace.ID])
+	var form []DriverForm
+	for _, result := range season.Results[lastRace.ID] {
+		var finishes []float64
+		for i := len(season.Races) - 1; i >= 0 && len(finishes) < window; i-- {
+			race := season.Races[i]
+			if race.Round > last {
+				continue
+			}
+			for _, r := range season.Results[race.ID] {
+				if r.DriverID == result.DriverID && r.Position > 0 && ClassifyStatus(r.Status).Finished() {
+					finishes = append(finishes, float64(r.Position))
+				}
+			}
+		}
+
+		entry := DriverForm{
+			DriverID:     result.DriverID,
+			TeamID:       result.TeamID,
+			MeanPosition: float64(fieldSize) * 0.75,
+			Spread:       3,
+			DNFRate:      dnfPrior,
+		}
+		if len(finishes) > 0 {
+			entry.MeanPosition, entry.Spread = MeanStdDev(finishes)
+			entry.Spread = max(entry.Spread, minFormSpread)
+		}
+		if rate, ok := dnfRates[result.TeamID]; ok {
+			entry.DNFRate = rate
+		}
+		form = append(form, entry)
+	}
+	sort.Slice(form, func(i, j int) bool { return form[i].DriverID < form[j].DriverID })
+	return form
+}
+
+// ProjectSeason simulates the remaining weekends opts.Runs times. In every
+// race each driver retires with their team's DNF rate, and the rest are
+// ordered by a draw from a normal distribution around their form. Runs with
+// the same seed give the same projection. Final ties are settled by the
+// current standings.
+func ProjectSeason(season SeasonResults, system PointsSystem, remaining []RemainingWeekend, opts ProjectionOptions) Projection {
+	if opts.Runs <= 0 {
+		opts.Runs = DefaultProjectionRuns
+	}
+	opts.Runs = min(opts.Runs, MaxProjectionRuns)
+	if opts.FormWindow <= 0 {
+		opts.FormWindow = DefaultFormWindow
+	}
+
+	form := CurrentForm(season, opts.FormWindow, DNFRates(season))
+	projection := Projection{Options: opts, RemainingRounds: len(remaining), Form: form}
+
+	driverTable := ComputeDriverStandings(season, 0)
+	constructorTable := ComputeConstructorStandings(season, 0)
+	driverIDs := make([]uint, len(driverTable))
+	driverPoints := make([]float64, len(driverTable))
+	driverIndex := make(map[uint]int, len(driverTable))
+	for i, standing := range driverTable {
+		driverIDs[i], driverPoints[i] = standing.DriverID, standing.Points
+		driverIndex[standing.DriverID] = i
+	}
+	teamIDs := make([]uint, len(constructorTable))
+	teamPoints := make([]float64, len(constructorTable))
+	teamIndex := make(map[uint]int, len(constructorTable))
+	for i, standing := range constructorTable {
+		teamIDs[i], teamPoints[i] = standing.TeamID, standing.Points
+		teamIndex[standing.TeamID] = i
+	}
+
+	driverTally := newPositionTally(driverIDs, driverPoints)
+	teamTally := newPositionTally(teamIDs, teamPoints)
+
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed))
+	simDrivers := make([]float64, len(driverPoints))
+	simTeams := make([]float64, len(teamPoints))
+	type draw struct {
+		form  DriverForm
+		score float64
+	}
+	order := make([]draw, 0, len(form))
+
+	finish := func(dnfScale float64, points func(position int) float64) {
+		order = order[:0]
+		for _, f := range form {
+			if rng.Float64() < f.DNFRate*dnfScale {
+				continue
+			}
+			order = append(order, draw{form: f, score: f.MeanPosition + rng.NormFloat64()*f.Spread})
+		}
+		sort.Slice(order, func(i, j int) bool { return order[i].score < order[j].score })
+		for i, d := range order {
+			scored := points(i + 1)
+			if scored == 0 {
+				continue
+			}
+			if k, ok := driverIndex[d.form.DriverID]; ok {
+				simDrivers[k] += scored
+			}
+			if k, ok := teamIndex[d.form.TeamID]; ok {
+				simTeams[k] += scored
+			}
+		}
+	}
+
+	for run := 0; run < opts.Runs; run++ {
+		copy(simDrivers, driverPoints)
+		copy(simTeams, teamPoints)
+		for _, weekend := range remaining {
+			if weekend.Sprint {
+				// A sprint is a third of the distance, so a third as likely to end early
+				finish(1.0/3, system.SprintPoints)
+			}
+			fastest := 0
+			if system.FastestLap > 0 {
+				top := system.FastestLapTop
+				if top == 0 {
+					top = len(form)
+				}
+				fastest = 1 + rng.IntN(max(1, min(top, len(form))))
+			}
+			finish(1, func(position int) float64 {
+				return system.RacePoints(position, position == fastest, 1)
+			})
+		}
+		driverTally.record(simDrivers)
+		teamTally.record(simTeams)
+	}
+
+	projection.Drivers = driverTally.entries(opts.Runs)
+	projection.Constructors = teamTally.entries(opts.Runs)
+	return projection
+}
+
+// positionTally counts how often each entry finished in each championship position
+type positionTally struct {
+	ids     []uint
+	current []float64
+	totals  []float64
+	counts  [][]int
+	order   []int
+}
+
+func newPositionTally(ids []uint, current []float64) *positionTally {
+	t := &positionTally{
+		ids:     ids,
+		current: current,
+		totals:  make([]float64, len(ids)),
+		counts:  make([][]int, len(ids)),
+		order:   make([]int, len(ids)),
+	}
+	for i := range t.counts {
+		t.counts[i] = make([]int, len(ids))
+	}
+	return t
+}
+
+// record ranks one run's points. The entries start in standings order, so a
+// stable sort leaves ties in that order.
+func (t *positionTally) record(points []float64) {
+	for i := range t.order {
+		t.order[i] = i
+	}
+	sort.SliceStable(t.order, func(a, b int) bool { return points[t.order[a]] > points[t.order[b]] })
+	for position, i := range t.order {
+		t.counts[i][position]++
+		t.totals[i] += points[i]
+	}
+}
+
+func (t *positionTally) entries(runs int) []ProjectedEntry {
+	entries := make([]ProjectedEntry, len(t.ids))
+	for i, id := range t.ids {
+		entry := ProjectedEntry{
+			ID:             id,
+			CurrentPoints:  t.current[i],
+			ExpectedPoints: t.totals[i] / float64(runs),
+			Positions:      make([]float64, len(t.ids)),
+		}
+		for position, count := range t.counts[i] {
+			entry.Positions[position] = float64(count) / float64(runs)
+		}
+		if len(entry.Positions) > 0 {
+			entry.ChampionProbability = entry.Positions[0]
+		}
+		entries[i] = entry
+	}
+	sort.SliceStable(entries, func(a, b int) bool {
+		if entries[a].ChampionProbability != entries[b].ChampionProbability {
+			return entries[a].ChampionProbability > entries[b].ChampionProbability
+		}
+		return entries[a].ExpectedPoints > entries[b].ExpectedPoints
+	})
+	return entries
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/f1-analytics/models"
+)
+
+// projectionSeason is two rounds of a four driver, two team season
+func projectionSeason() SeasonResults {
+	season := SeasonResults{
+		Season:  2024,
+		Results: make(map[uint][]models.RaceDriver),
+	}
+	finishes := [][]uint{{1, 2, 3, 4}, {2, 1, 4, 3}}
+	for round, order := range finishes {
+		race := models.Race{Season: 2024, Round: round + 1, Status: "Completed"}
+		race.ID = uint(round + 1)
+		season.Races = append(season.Races, race)
+
+		system, _ := PointsSystemForSeason(2024)
+		for i, driverID := range order {
+			season.Results[race.ID] = append(season.Results[race.ID], models.RaceDriver{
+				DriverID: driverID,
+				RaceID:   race.ID,
+				TeamID:   (driverID + 1) / 2,
+				Position: i + 1,
+				Points:   system.RacePoints(i+1, false, 1),
+				Status:   "Finished",
+			})
+		}
+	}
+	return season
+}
+
+func projectionWeekends() []RemainingWeekend {
+	return []RemainingWeekend{
+		{Round: 3, RaceID: 3},
+		{Round: 4, RaceID: 4, Sprint: true},
+		{Round: 5, RaceID: 5},
+	}
+}
+
+func TestProjectSeasonSameSeed(t *testing.T) {
+	season := projectionSeason()
+	system, _ := PointsSystemForSeason(season.Season)
+	opts := ProjectionOptions{Runs: 500, Seed: 42}
+
+	first := ProjectSeason(season, system, projectionWeekends(), opts)
+	second := ProjectSeason(season, system, projectionWeekends(), opts)
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("projections with the same seed differ:\n%+v\n%+v", first, second)
+	}
+}
+
+func TestProjectSeasonDifferentSeeds(t *testing.T) {
+	season := projectionSeason()
+	system, _ := PointsSystemForSeason(season.Season)
+
+	first := ProjectSeason(season, system, projectionWeekends(), ProjectionOptions{Runs: 500, Seed: 1})
+	second := ProjectSeason(season, system, projectionWeekends(), ProjectionOptions{Runs: 500, Seed: 2})
+	if reflect.DeepEqual(first.Drivers, second.Drivers) {
+		t.Fatalf("projections with different seeds are identical: %+v", first.Drivers)
+	}
+}
+
+func TestProjectSeasonClinchedLeader(t *testing.T) {
+	season := projectionSeason()
+	// Driver 1 leads by more than one race can make up
+	season.Results[1][0].Points += 100
+	system, _ := PointsSystemForSeason(season.Season)
+	remaining := []RemainingWeekend{{Round: 3, RaceID: 3}}
+
+	projection := ProjectSeason(season, system, remaining, ProjectionOptions{Runs: 500, Seed: 7})
+	for _, entry := range projection.Drivers {
+		want := 0.0
+		if entry.ID == 1 {
+			want = 1
+		}
+		if entry.ChampionProbability != want {
+			t.Errorf("driver %d: champion probability %v, want %v", entry.ID, entry.ChampionProbability, want)
+		}
+	}
+}
+
+func TestProjectSeasonProbabilitiesSumToOne(t *testing.T) {
+	season := projectionSeason()
+	system, _ := PointsSystemForSeason(season.Season)
+	projection := ProjectSeason(season, system, projectionWeekends(), ProjectionOptions{Runs: 500, Seed: 3})
+
+	for name, entries := range map[string][]ProjectedEntry{"drivers": projection.Drivers, "constructors": projection.Constructors} {
+		champion := 0.0
+		byPosition := make([]float64, len(entries))
+		for _, entry := range entries {
+			champion += entry.ChampionProbability
+			total := 0.0
+			for position, p := range entry.Positions {
+				total += p
+				byPosition[position] += p
+			}
+			if !approxEqual(total, 1) {
+				t.Errorf("%s: entry %d's positions sum to %v", name, entry.ID, total)
+			}
+		}
+		if !approxEqual(champion, 1) {
+			t.Errorf("%s: champion probabilities sum to %v", name, champion)
+		}
+		for position, total := range byPosition {
+			if !approxEqual(total, 1) {
+				t.Errorf("%s: probabilities of position %d sum to %v", name, position+1, total)
+			}
+		}
+	}
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
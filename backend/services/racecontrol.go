@@ -0,0 +1,93 @@
+package services
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/f1-analytics/models"
+)
+
+// Kinds of neutralisation
+const (
+	NeutralisedSafetyCar = "SC"
+	NeutralisedVirtual   = "VSC"
+	NeutralisedRedFlag   = "RED"
+)
+
+// NeutralisedPeriod is a stretch of a race run behind the safety car, under a
+// virtual safety car or stopped by a red flag, in the leader's laps
+type NeutralisedPeriod struct {
+	Kind     string `json:"kind"`
+	StartLap int    `json:"start_lap"`
+	EndLap   int    `json:"end_lap"`
+}
+
+// NeutralisedPeriods reads the safety car, virtual safety car and red flag
+// periods from race control messages. A period still open at the last
+// message runs to lastLap.
+func NeutralisedPeriods(messages []models.RaceControlMessage, lastLap int) []NeutralisedPeriod {
+	sorted := make([]models.RaceControlMessage, len(messages))
+	copy(sorted, messages)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+
+	var periods []NeutralisedPeriod
+	open := make(map[string]int) // Kind to index in periods
+	start := func(kind string, lap int) {
+		if _, ok := open[kind]; ok {
+			return
+		}
+		open[kind] = len(periods)
+		periods = append(periods, NeutralisedPeriod{Kind: kind, StartLap: lap, EndLap: lap})
+	}
+	end := func(kind string, lap int) {
+		i, ok := open[kind]
+		if !ok {
+			return
+		}
+		periods[i].EndLap = max(periods[i].StartLap, lap)
+		delete(open, kind)
+	}
+
+	for _, message := range sorted {
+		text := strings.ToUpper(message.Message)
+		switch {
+		case strings.Contains(text, "VIRTUAL SAFETY CAR DEPLOYED"):
+			start(NeutralisedVirtual, message.LapNumber)
+		case strings.Contains(text, "VIRTUAL SAFETY CAR ENDING"):
+			end(NeutralisedVirtual, message.LapNumber)
+		case strings.Contains(text, "SAFETY CAR DEPLOYED"):
+			// A restart after a red flag is often led by the safety car
+			end(NeutralisedRedFlag, message.LapNumber)
+			start(NeutralisedSafetyCar, message.LapNumber)
+		case strings.Contains(text, "SAFETY CAR IN THIS LAP"):
+			end(NeutralisedSafetyCar, message.LapNumber)
+		case strings.EqualFold(message.Flag, "RED"):
+			start(NeutralisedRedFlag, message.LapNumber)
+		case strings.EqualFold(message.Flag, "GREEN") && message.Scope == "Track":
+			end(NeutralisedRedFlag, message.LapNumber)
+		}
+	}
+	for kind := range open {
+		periods[open[kind]].EndLap = max(periods[open[kind]].StartLap, lastLap)
+	}
+	return periods
+}
+
+// FlagNeutralisedLaps marks the laps run at least partly behind the safety
+// car or under a virtual safety car
+func FlagNeutralisedLaps(laps []models.Lap, periods []NeutralisedPeriod) {
+	for i := range laps {
+		lap := &laps[i]
+		for _, period := range periods {
+			if lap.LapNumber < period.StartLap || lap.LapNumber > period.EndLap {
+				continue
+			}
+			switch period.Kind {
+			case NeutralisedSafetyCar:
+				lap.SafetyCar = true
+			case NeutralisedVirtual:
+				lap.VirtualSafetyCar = true
+			}
+		}
+	}
+}
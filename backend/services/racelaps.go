@@ -0,0 +1,50 @@
+package services
+
+import "strings"
+
+// scheduledLaps lists the scheduled Grand Prix distance of each circuit in
+// laps, from the season the distance applied. OpenF1 only publishes the laps
+// run, so only the circuits of the seasons it covers are listed.
+var scheduledLaps = []struct {
+	circuit string // Circuit short name as OpenF1 publishes it, in lower case
+	from    int
+	laps    int
+}{
+	{"sakhir", 2023, 57},
+	{"jeddah", 2023, 50},
+	{"melbourne", 2023, 58},
+	{"shanghai", 2023, 56},
+	{"baku", 2023, 51},
+	{"miami", 2023, 57},
+	{"imola", 2023, 63},
+	{"monte carlo", 2023, 78},
+	{"catalunya", 2023, 66},
+	{"montreal", 2023, 70},
+	{"spielberg", 2023, 71},
+	{"silverstone", 2023, 52},
+	{"hungaroring", 2023, 70},
+	{"spa-francorchamps", 2023, 44},
+	{"zandvoort", 2023, 72},
+	{"monza", 2023, 53},
+	{"singapore", 2023, 62},
+	{"suzuka", 2023, 53},
+	{"lusail", 2023, 57},
+	{"austin", 2023, 56},
+	{"mexico city", 2023, 71},
+	{"interlagos", 2023, 71},
+	{"las vegas", 2023, 50},
+	{"yas marina circuit", 2023, 58},
+}
+
+// ScheduledLaps returns the laps a Grand Prix at a circuit was scheduled to
+// run in a season, or zero when it is not known
+func ScheduledLaps(circuit string, season int) int {
+	name := strings.ToLower(strings.TrimSpace(circuit))
+	laps, from := 0, 0
+	for _, distance := range scheduledLaps {
+		if distance.circuit == name && distance.from <= season && distance.from >= from {
+			laps, from = distance.laps, distance.from
+		}
+	}
+	return laps
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/f1-analytics/models"
+)
+
+const (
+	// InitialRating is every driver's rating before their first race
+	InitialRating = 1500.0
+	// TeammateK scales the rating change from the head-to-head with a teammate
+	TeammateK = 24.0
+	// FieldK scales the change from the head-to-heads with the rest of the
+	// field, which say as much about the car as the driver
+	FieldK = 8.0
+	// SeasonRegression pulls every rating this far back towards InitialRating between seasons
+	SeasonRegression = 0.2
+)
+
+// RatingChange is a driver's rating after one race
+type RatingChange struct {
+	DriverID uint      `json:"driver_id"`
+	RaceID   uint      `json:"race_id"`
+	Season   int       `json:"season"`
+	Round    int       `json:"round"`
+	Date     time.Time `json:"date"`
+	Before   float64   `json:"before"`
+	Rating   float64   `json:"rating"`
+	Change   float64   `json:"change"`
+}
+
+// expectedScore is the chance a driver rated a beats one rated b
+func expectedScore(a, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// ratedResult reports whether a result is compared with the rest of the
+// field. Retirements are left out, as most are down to the car.
+func ratedResult(result models.RaceDriver) bool {
+	return result.Position > 0 && ClassifyStatus(result.Status).Finished()
+}
+
+// RateDrivers runs an Elo-style rating over the seasons in order. After every
+// race each driver's rating moves by TeammateK times their surplus over the
+// expected result against their teammate, plus FieldK times their mean
+// surplus against everyone else, so beating a teammate counts for far more
+// than beating a slower car. Seasons must be given oldest first.
+func RateDrivers(seasons []SeasonResults) []RatingChange {
+	ratings := make(map[uint]float64)
+	rating := func(driverID uint) float64 {
+		if r, ok := ratings[driverID]; ok {
+			return r
+		}
+		return InitialRating
+	}
+
+	var changes []RatingChange
+	for _, season := range seasons {
+		regressed := make(map[uint]float64)
+		for driverID, r := range ratings {
+			ratings[driverID] = r + (InitialRating-r)*SeasonRegression
+			regressed[driverID] = r
+		}
+
+		for _, race := range season.Races {
+			var rated []models.RaceDriver
+			for _, result := range season.Results[race.ID] {
+				if ratedResult(result) {
+					rated = append(rated, result)
+				}
+			}
+			if len(rated) < 2 {
+				continue
+			}
+
+			deltas := make(map[uint]float64, len(rated))
+			for _, a := range rated {
+				teamA := season.TeamOf(race.ID, a.DriverID)
+				teammate, teammates := 0.0, 0
+				field, others := 0.0, 0
+				for _, b := range rated {
+					if a.DriverID == b.DriverID {
+						continue
+					}
+					score := 0.0
+					if a.Position < b.Position {
+						score = 1
+					}
+					surplus := score - expectedScore(rating(a.DriverID), rating(b.DriverID))
+					if teamA != 0 && season.TeamOf(race.ID, b.DriverID) == teamA {
+						teammate += surplus
+						teammates++
+					} else {
+						field += surplus
+						others++
+					}
+				}
+				if teammates > 0 {
+					deltas[a.DriverID] += TeammateK * teammate / float64(teammates)
+				}
+				if others > 0 {
+					deltas[a.DriverID] += FieldK * field / float64(others)
+				}
+			}
+
+			for _, result := range rated {
+				before := rating(result.DriverID)
+				after := before + deltas[result.DriverID]
+				ratings[result.DriverID] = after
+				// The first race of a season also carries the regression from the last
+				if previous, ok := regressed[result.DriverID]; ok {
+					before = previous
+					delete(regressed, result.DriverID)
+				}
+				changes = append(changes, RatingChange{
+					DriverID: result.DriverID,
+					RaceID:   race.ID,
+					Season:   season.Season,
+					Round:    race.Round,
+					Date:     race.Date,
+					Before:   before,
+					Rating:   after,
+					Change:   after - before,
+				})
+			}
+		}
+	}
+	return changes
+}
+
+// RatingTable ranks drivers by their latest rating. Changes must be in the
+// order RateDrivers returns them.
+func RatingTable(changes []RatingChange) []RatingChange {
+	latest := make(map[uint]RatingChange)
+	for _, change := range changes {
+		latest[change.DriverID] = change
+	}
+
+	table := make([]RatingChange, 0, len(latest))
+	for _, change := range latest {
+		table = append(table, change)
+	}
+	sort.Slice(table, func(i, j int) bool {
+		if table[i].Rating != table[j].Rating {
+			return table[i].Rating > table[j].Rating
+		}
+		return table[i].DriverID < table[j].DriverID
+	})
+	return table
+}
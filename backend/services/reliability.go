@@ -0,0 +1,202 @@
+package services
+
+import (
+	"sort"
+	"strings"
+)
+
+// UnknownPowerUnit groups teams without a recorded engine supplier
+const UnknownPowerUnit = "Unknown"
+
+// ReliabilityStats counts how a set of race starts ended
+type ReliabilityStats struct {
+	Entries      int            `json:"entries"`
+	Starts       int            `json:"starts"`
+	Finished     int            `json:"finished"`
+	Lapped       int            `json:"lapped"`
+	Accidents    int            `json:"accidents"`
+	Collisions   int            `json:"collisions"`
+	Mechanical   int            `json:"mechanical"`
+	Retired      int            `json:"retired"` // Retirements without a recorded cause
+	Disqualified int            `json:"disqualified"`
+	DNS          int            `json:"dns"`
+	MechanicalBy map[string]int `json:"mechanical_by"` // Mechanical retirements by subtype
+	FinishRate   float64        `json:"finish_rate"`   // Classified finishes per start
+	DNFRate      float64        `json:"dnf_rate"`      // Retirements of any cause per start
+	FailureRate  *float64       `json:"failure_rate"`  // Mechanical retirements per start, null when no retirement had a recorded cause
+}
+
+// TeamReliability is a team's reliability over a season
+type TeamReliability struct {
+	TeamID    uint   `json:"team_id"`
+	PowerUnit string `json:"power_unit"`
+	ReliabilityStats
+}
+
+// PowerUnitReliability is the reliability of every car with one supplier's
+// power unit
+type PowerUnitReliability struct {
+	PowerUnit string `json:"power_unit"`
+	Teams     []uint `json:"teams"`
+	ReliabilityStats
+}
+
+// StatusCount is how often a raw status was seen and where it was classified
+type StatusCount struct {
+	Status string `json:"status"`
+	StatusClass
+	Count int `json:"count"`
+}
+
+// SeasonReliability breaks a season's results down by how they ended.
+// Results ingested from OpenF1 only record that a car retired, so a season
+// may have no retirement causes at all.
+type SeasonReliability struct {
+	Season      int                    `json:"season"`
+	CausesKnown bool                   `json:"causes_known"` // Some retirement of the season had a recorded cause
+	Overall     ReliabilityStats       `json:"overall"`
+	Teams       []TeamReliability      `json:"teams"`
+	PowerUnits  []PowerUnitReliability `json:"power_units"`
+	Statuses    []StatusCount          `json:"statuses"`
+}
+
+// add counts one result
+func (s *ReliabilityStats) add(class StatusClass) {
+	s.Entries++
+	if !class.Started() {
+		s.DNS++
+		return
+	}
+	s.Starts++
+	switch class.Category {
+	case StatusFinished:
+		s.Finished++
+	case StatusLapped:
+		s.Lapped++
+	case StatusAccident:
+		s.Accidents++
+	case StatusCollision:
+		s.Collisions++
+	case StatusMechanical:
+		s.Mechanical++
+		if s.MechanicalBy == nil {
+			s.MechanicalBy = make(map[string]int)
+		}
+		s.MechanicalBy[class.Subtype]++
+	case StatusRetired:
+		s.Retired++
+	case StatusDisqualified:
+		s.Disqualified++
+	}
+}
+
+// rates works out the rates once every result is counted
+func (s *ReliabilityStats) rates() {
+	if s.MechanicalBy == nil {
+		s.MechanicalBy = map[string]int{}
+	}
+	if s.Starts == 0 {
+		return
+	}
+	starts := float64(s.Starts)
+	s.FinishRate = float64(s.Finished+s.Lapped) / starts
+	s.DNFRate = float64(s.Accidents+s.Collisions+s.Mechanical+s.Retired) / starts
+	if s.causesKnown() {
+		failureRate := float64(s.Mechanical) / starts
+		s.FailureRate = &failureRate
+	}
+}
+
+// causesKnown reports whether the failure rate means anything: there were no
+// retirements, or at least one of them had a recorded cause
+func (s *ReliabilityStats) causesKnown() bool {
+	return s.Accidents+s.Collisions+s.Mechanical > 0 || s.Retired == 0
+}
+
+// SeasonReliabilityStats classifies every result of a season and totals them
+// per team, per power unit supplier and overall. powerUnits maps each team to
+// its engine supplier; teams missing from it count as UnknownPowerUnit.
+func SeasonReliabilityStats(season SeasonResults, powerUnits map[uint]string) SeasonReliability {
+	reliability := SeasonReliability{Season: season.Season}
+	teams := make(map[uint]*TeamReliability)
+	suppliers := make(map[string]*PowerUnitReliability)
+	statuses := make(map[string]*StatusCount)
+
+	for _, race := range season.Races {
+		for _, result := range season.Results[race.ID] {
+			class := ClassifyStatus(result.Status)
+			reliability.Overall.add(class)
+
+			raw := strings.TrimSpace(result.Status)
+			count, ok := statuses[strings.ToLower(raw)]
+			if !ok {
+				count = &StatusCount{Status: raw, StatusClass: class}
+				statuses[strings.ToLower(raw)] = count
+			}
+			count.Count++
+
+			if result.TeamID == 0 {
+				continue
+			}
+			team, ok := teams[result.TeamID]
+			if !ok {
+				supplier := strings.TrimSpace(powerUnits[result.TeamID])
+				if supplier == "" {
+					supplier = UnknownPowerUnit
+				}
+				team = &TeamReliability{TeamID: result.TeamID, PowerUnit: supplier}
+				teams[result.TeamID] = team
+			}
+			team.add(class)
+
+			pu, ok := suppliers[team.PowerUnit]
+			if !ok {
+				pu = &PowerUnitReliability{PowerUnit: team.PowerUnit}
+				suppliers[team.PowerUnit] = pu
+			}
+			pu.add(class)
+		}
+	}
+
+	reliability.Overall.rates()
+	reliability.CausesKnown = reliability.Overall.Accidents+reliability.Overall.Collisions+reliability.Overall.Mechanical > 0
+	reliability.Teams = make([]TeamReliability, 0, len(teams))
+	for _, team := range teams {
+		team.rates()
+		reliability.Teams = append(reliability.Teams, *team)
+		pu := suppliers[team.PowerUnit]
+		pu.Teams = append(pu.Teams, team.TeamID)
+	}
+	// Retirements of any cause rank the teams, as the causes are often unknown
+	sort.Slice(reliability.Teams, func(i, j int) bool {
+		if reliability.Teams[i].DNFRate != reliability.Teams[j].DNFRate {
+			return reliability.Teams[i].DNFRate < reliability.Teams[j].DNFRate
+		}
+		return reliability.Teams[i].TeamID < reliability.Teams[j].TeamID
+	})
+
+	reliability.PowerUnits = make([]PowerUnitReliability, 0, len(suppliers))
+	for _, pu := range suppliers {
+		pu.rates()
+		sort.Slice(pu.Teams, func(i, j int) bool { return pu.Teams[i] < pu.Teams[j] })
+		reliability.PowerUnits = append(reliability.PowerUnits, *pu)
+	}
+	sort.Slice(reliability.PowerUnits, func(i, j int) bool {
+		if reliability.PowerUnits[i].DNFRate != reliability.PowerUnits[j].DNFRate {
+			return reliability.PowerUnits[i].DNFRate < reliability.PowerUnits[j].DNFRate
+		}
+		return reliability.PowerUnits[i].PowerUnit < reliability.PowerUnits[j].PowerUnit
+	})
+
+	reliability.Statuses = make([]StatusCount, 0, len(statuses))
+	for _, count := range statuses {
+		reliability.Statuses = append(reliability.Statuses, *count)
+	}
+	sort.Slice(reliability.Statuses, func(i, j int) bool {
+		if reliability.Statuses[i].Count != reliability.Statuses[j].Count {
+			return reliability.Statuses[i].Count > reliability.Statuses[j].Count
+		}
+		return reliability.Statuses[i].Status < reliability.Statuses[j].Status
+	})
+	return reliability
+}
@@ -0,0 +1,174 @@
+package services
+
+import "strings"
+
+// RemainingWeekend is a round still to be run and the most it can pay out
+type RemainingWeekend struct {
+	Round           int     `json:"round"`
+	RaceID          uint    `json:"race_id"`
+	Name            string  `json:"name"`
+	Sprint          bool    `json:"sprint"`
+	MaxDriverPoints float64 `json:"max_driver_points"`
+	MaxTeamPoints   float64 `json:"max_team_points"` // One-two in the race and the sprint
+}
+
+// ScenarioEntry is a championship contender's current score
+type ScenarioEntry struct {
+	ID     uint
+	Points float64
+}
+
+// FinishRequirement says how well a rival may finish at the next race for a
+// given result of the subject. Each RivalAtBest is the highest place the
+// rival can take, one past the points when the rival must not score, and zero
+// when no rival finish is enough.
+type FinishRequirement struct {
+	Position           int     `json:"position"`
+	Points             float64 `json:"points"`
+	ClinchRivalAtBest  int     `json:"clinch_rival_at_best"`
+	SurviveRivalAtBest int     `json:"survive_rival_at_best"`
+}
+
+// RivalScenario is the subject's position against one rival. Margins are in
+// points scored at the next weekend, the subject's less the rival's.
+type RivalScenario struct {
+	RivalID          uint                `json:"rival_id"`
+	Gap              float64             `json:"gap"`           // Subject's points less the rival's
+	ClinchMargin     float64             `json:"clinch_margin"` // Must be beaten to be out of the rival's reach after the next weekend
+	ClinchPossible   bool                `json:"clinch_possible"`
+	SurvivalMargin   float64             `json:"survival_margin"` // Most the subject can fall back by and still be able to catch the rival
+	SurvivalPossible bool                `json:"survival_possible"`
+	Finishes         []FinishRequirement `json:"finishes,omitempty"`
+}
+
+// ChampionshipScenario sums up what a contender can still achieve
+type ChampionshipScenario struct {
+	ID                 uint              `json:"id"`
+	Position           int               `json:"position"`
+	Points             float64           `json:"points"`
+	RemainingRounds    int               `json:"remaining_rounds"`
+	RemainingPoints    float64           `json:"remaining_points"`
+	Champion           bool              `json:"champion"`
+	CanWin             bool              `json:"can_win"`
+	CanClinchNextRound bool              `json:"can_clinch_next_round"`
+	NextRound          *RemainingWeekend `json:"next_round,omitempty"`
+	Rivals             []RivalScenario   `json:"rivals"`
+}
+
+// RemainingWeekends lists the rounds of a season still without results,
+// leaving out cancelled ones, with the most a driver and a team can score
+func RemainingWeekends(season SeasonResults, system PointsSystem) []RemainingWeekend {
+	last := season.LastRound()
+	var remaining []RemainingWeekend
+	for _, race := range season.Races {
+		if race.Round <= last || strings.EqualFold(race.Status, "Cancelled") {
+			continue
+		}
+		weekend := RemainingWeekend{
+			Round:           race.Round,
+			RaceID:          race.ID,
+			Name:            race.Name,
+			Sprint:          !race.SprintTime.IsZero() && len(system.Sprint) > 0,
+			MaxDriverPoints: system.RacePoints(1, true, 1),
+			MaxTeamPoints:   system.RacePoints(1, true, 1) + system.RacePoints(2, false, 1),
+		}
+		if weekend.Sprint {
+			weekend.MaxDriverPoints += system.SprintPoints(1)
+			weekend.MaxTeamPoints += system.SprintPoints(1) + system.SprintPoints(2)
+		}
+		remaining = append(remaining, weekend)
+	}
+	return remaining
+}
+
+// ChampionshipScenarios works out whether the subject can still win the
+// championship and what it needs at the next weekend against every rival who
+// can still finish ahead. Clinching needs a strict lead; staying alive allows
+// a tie, which countback would settle. table must be in championship order
+// and maxPoints holds what each remaining weekend can pay out, in order.
+func ChampionshipScenarios(table []ScenarioEntry, subjectID uint, maxPoints []float64) ChampionshipScenario {
+	scenario := ChampionshipScenario{ID: subjectID, RemainingRounds: len(maxPoints), Rivals: []RivalScenario{}}
+	for i, entry := range table {
+		if entry.ID == subjectID {
+			scenario.Position = i + 1
+			scenario.Points = entry.Points
+		}
+	}
+	for _, points := range maxPoints {
+		scenario.RemainingPoints += points
+	}
+	next, afterNext := 0.0, scenario.RemainingPoints
+	if len(maxPoints) > 0 {
+		next = maxPoints[0]
+		afterNext -= next
+	}
+
+	scenario.Champion = true
+	scenario.CanWin = true
+	scenario.CanClinchNextRound = len(maxPoints) > 0
+	for _, entry := range table {
+		if entry.ID == subjectID {
+			continue
+		}
+		gap := scenario.Points - entry.Points
+		if -gap > scenario.RemainingPoints {
+			scenario.CanWin = false
+		}
+		if gap > scenario.RemainingPoints {
+			continue
+		}
+		scenario.Champion = false
+
+		rival := RivalScenario{
+			RivalID:        entry.ID,
+			Gap:            gap,
+			ClinchMargin:   afterNext - gap,
+			SurvivalMargin: gap + afterNext,
+		}
+		rival.ClinchPossible = len(maxPoints) > 0 && rival.ClinchMargin < next
+		rival.SurvivalPossible = len(maxPoints) > 0 && -rival.SurvivalMargin <= next
+		if !rival.ClinchPossible {
+			scenario.CanClinchNextRound = false
+		}
+		scenario.Rivals = append(scenario.Rivals, rival)
+	}
+	if !scenario.CanWin {
+		scenario.Champion = false
+		scenario.CanClinchNextRound = false
+	}
+	if scenario.Champion {
+		scenario.CanClinchNextRound = false
+	}
+	return scenario
+}
+
+// RaceFinishRequirements fills in, for a weekend without a sprint, how well
+// the rival may finish for each points-scoring result of the subject. The
+// fastest lap bonus is ignored.
+func RaceFinishRequirements(rival *RivalScenario, system PointsSystem) {
+	for position := 1; position <= len(system.Race)+1; position++ {
+		points := system.RacePoints(position, false, 1)
+		rival.Finishes = append(rival.Finishes, FinishRequirement{
+			Position:           position,
+			Points:             points,
+			ClinchRivalAtBest:  rivalAtBest(system, position, points-rival.ClinchMargin, true),
+			SurviveRivalAtBest: rivalAtBest(system, position, points+rival.SurvivalMargin, false),
+		})
+	}
+}
+
+// rivalAtBest returns the highest place, other than the subject's, at which
+// the rival scores less than allowed, or no more than allowed when not strict
+func rivalAtBest(system PointsSystem, subjectPosition int, allowed float64, strict bool) int {
+	for position := 1; position <= len(system.Race)+1; position++ {
+		// Any number of cars can finish outside the points
+		if position == subjectPosition && position <= len(system.Race) {
+			continue
+		}
+		points := system.RacePoints(position, false, 1)
+		if points < allowed || !strict && points == allowed {
+			return position
+		}
+	}
+	return 0
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/f1-analytics/models"
+)
+
+// SectorBest is the quickest time through one sector and who set it
+type SectorBest struct {
+	Sector    int     `json:"sector"`
+	DriverID  uint    `json:"driver_id"`
+	LapNumber int     `json:"lap_number"`
+	Time      float64 `json:"time"` // Seconds
+}
+
+// DriverSectors is a driver's best sectors and what they add up to
+type DriverSectors struct {
+	DriverID          uint       `json:"driver_id"`
+	BestSectors       [3]float64 `json:"best_sectors"` // Seconds, zero when no valid time was set
+	TheoreticalBest   float64    `json:"theoretical_best"`
+	BestLap           float64    `json:"best_lap"`
+	BestLapNumber     int        `json:"best_lap_number"`
+	GapToTheoretical  float64    `json:"gap_to_theoretical"` // Best lap minus theoretical best
+	PurpleSectors     []int      `json:"purple_sectors"`     // Sectors in which the driver was quickest of the session
+	GapToSessionIdeal float64    `json:"gap_to_session_ideal"`
+}
+
+// SectorAnalysis covers every driver's sectors in one session
+type SectorAnalysis struct {
+	Session       string          `json:"session"`
+	PurpleSectors []SectorBest    `json:"purple_sectors"`
+	IdealLap      float64         `json:"ideal_lap"` // Sum of the session's purple sectors
+	Drivers       []DriverSectors `json:"drivers"`   // Ordered by theoretical best
+}
+
+// AnalyseSectors finds each driver's best sectors and theoretical best lap,
+// along with the session-wide purple sectors
+func AnalyseSectors(session string, laps []models.Lap) SectorAnalysis {
+	analysis := SectorAnalysis{Session: session}
+	byDriver := make(map[uint]*DriverSectors)
+	purple := make([]SectorBest, 3)
+
+	for _, lap := range laps {
+		driver, ok := byDriver[lap.DriverID]
+		if !ok {
+			driver = &DriverSectors{DriverID: lap.DriverID}
+			byDriver[lap.DriverID] = driver
+		}
+
+		for i, sector := range []float64{lap.Sector1.Seconds(), lap.Sector2.Seconds(), lap.Sector3.Seconds()} {
+			if sector <= 0 {
+				continue
+			}
+			if driver.BestSectors[i] == 0 || sector < driver.BestSectors[i] {
+				driver.BestSectors[i] = sector
+			}
+			if purple[i].Time == 0 || sector < purple[i].Time {
+				purple[i] = SectorBest{Sector: i + 1, DriverID: lap.DriverID, LapNumber: lap.LapNumber, Time: sector}
+			}
+		}
+
+		if t := lap.LapTime.Seconds(); t > 0 && (driver.BestLap == 0 || t < driver.BestLap) {
+			driver.BestLap = t
+			driver.BestLapNumber = lap.LapNumber
+		}
+	}
+
+	for _, best := range purple {
+		if best.Time > 0 {
+			analysis.PurpleSectors = append(analysis.PurpleSectors, best)
+		}
+	}
+	if len(analysis.PurpleSectors) == 3 {
+		for _, best := range analysis.PurpleSectors {
+			analysis.IdealLap += best.Time
+		}
+	}
+
+	for _, driver := range byDriver {
+		complete := driver.BestSectors[0] > 0 && driver.BestSectors[1] > 0 && driver.BestSectors[2] > 0
+		if complete {
+			driver.TheoreticalBest = driver.BestSectors[0] + driver.BestSectors[1] + driver.BestSectors[2]
+			if driver.BestLap > 0 {
+				driver.GapToTheoretical = driver.BestLap - driver.TheoreticalBest
+			}
+			if analysis.IdealLap > 0 {
+				driver.GapToSessionIdeal = driver.TheoreticalBest - analysis.IdealLap
+			}
+		}
+		for _, best := range analysis.PurpleSectors {
+			if best.DriverID == driver.DriverID {
+				driver.PurpleSectors = append(driver.PurpleSectors, best.Sector)
+			}
+		}
+		analysis.Drivers = append(analysis.Drivers, *driver)
+	}
+
+	// Drivers without a full set of sectors go last
+	sort.Slice(analysis.Drivers, func(i, j int) bool {
+		a, b := analysis.Drivers[i], analysis.Drivers[j]
+		if (a.TheoreticalBest == 0) != (b.TheoreticalBest == 0) {
+			return a.TheoreticalBest != 0
+		}
+		if a.TheoreticalBest != b.TheoreticalBest {
+			return a.TheoreticalBest < b.TheoreticalBest
+		}
+		return a.DriverID < b.DriverID
+	})
+
+	return analysis
+}
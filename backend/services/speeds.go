@@ -0,0 +1,130 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/f1-analytics/models"
+)
+
+// SpeedSummary summarises the speeds recorded at one measuring point, km/h
+type SpeedSummary struct {
+	Samples int     `json:"samples"`
+	Max     float64 `json:"max"`
+	Median  float64 `json:"median"`
+	Spread  float64 `json:"spread"` // Standard deviation
+}
+
+// SpeedProfile holds the summaries at both intermediates and the speed trap
+type SpeedProfile struct {
+	I1        SpeedSummary `json:"i1"`
+	I2        SpeedSummary `json:"i2"`
+	SpeedTrap SpeedSummary `json:"speed_trap"`
+}
+
+// DriverSpeeds is a driver's speed profile for a session
+type DriverSpeeds struct {
+	DriverID uint `json:"driver_id"`
+	SpeedProfile
+}
+
+// TeamSpeeds is a team's speed profile for a session, pooling both cars
+type TeamSpeeds struct {
+	TeamID uint `json:"team_id"`
+	SpeedProfile
+	TrapDeltaToFastest float64 `json:"trap_delta_to_fastest"` // Median trap speed behind the quickest team, km/h
+}
+
+// speedSamples collects raw speeds per measuring point
+type speedSamples struct {
+	i1, i2, trap []float64
+}
+
+func (s *speedSamples) add(lap models.Lap) {
+	if lap.I1Speed > 0 {
+		s.i1 = append(s.i1, float64(lap.I1Speed))
+	}
+	if lap.I2Speed > 0 {
+		s.i2 = append(s.i2, float64(lap.I2Speed))
+	}
+	if lap.SpeedTrap > 0 {
+		s.trap = append(s.trap, float64(lap.SpeedTrap))
+	}
+}
+
+func (s *speedSamples) profile() SpeedProfile {
+	return SpeedProfile{
+		I1:        summariseSpeeds(s.i1),
+		I2:        summariseSpeeds(s.i2),
+		SpeedTrap: summariseSpeeds(s.trap),
+	}
+}
+
+func summariseSpeeds(values []float64) SpeedSummary {
+	if len(values) == 0 {
+		return SpeedSummary{}
+	}
+	maxSpeed := values[0]
+	for _, v := range values {
+		maxSpeed = max(maxSpeed, v)
+	}
+	_, spread := MeanStdDev(values)
+	return SpeedSummary{
+		Samples: len(values),
+		Max:     maxSpeed,
+		Median:  Median(values),
+		Spread:  spread,
+	}
+}
+
+// SummariseSpeeds builds speed profiles per driver and per team for a
+// session. teamOf resolves a driver's team; drivers it returns zero for are
+// left out of the team summary.
+func SummariseSpeeds(laps []models.Lap, teamOf func(driverID uint) uint) ([]DriverSpeeds, []TeamSpeeds) {
+	byDriver := make(map[uint]*speedSamples)
+	byTeam := make(map[uint]*speedSamples)
+	for _, lap := range laps {
+		if byDriver[lap.DriverID] == nil {
+			byDriver[lap.DriverID] = &speedSamples{}
+		}
+		byDriver[lap.DriverID].add(lap)
+
+		if teamID := teamOf(lap.DriverID); teamID != 0 {
+			if byTeam[teamID] == nil {
+				byTeam[teamID] = &speedSamples{}
+			}
+			byTeam[teamID].add(lap)
+		}
+	}
+
+	drivers := make([]DriverSpeeds, 0, len(byDriver))
+	for driverID, samples := range byDriver {
+		drivers = append(drivers, DriverSpeeds{DriverID: driverID, SpeedProfile: samples.profile()})
+	}
+	sort.Slice(drivers, func(i, j int) bool {
+		if drivers[i].SpeedTrap.Max != drivers[j].SpeedTrap.Max {
+			return drivers[i].SpeedTrap.Max > drivers[j].SpeedTrap.Max
+		}
+		return drivers[i].DriverID < drivers[j].DriverID
+	})
+
+	teams := make([]TeamSpeeds, 0, len(byTeam))
+	fastest := 0.0
+	for teamID, samples := range byTeam {
+		team := TeamSpeeds{TeamID: teamID, SpeedProfile: samples.profile()}
+		fastest = max(fastest, team.SpeedTrap.Median)
+		teams = append(teams, team)
+	}
+	for i := range teams {
+		if teams[i].SpeedTrap.Samples > 0 {
+			teams[i].TrapDeltaToFastest = fastest - teams[i].SpeedTrap.Median
+		}
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		if teams[i].SpeedTrap.Median != teams[j].SpeedTrap.Median {
+			return teams[i].SpeedTrap.Median > teams[j].SpeedTrap.Median
+		}
+		return teams[i].TeamID < teams[j].TeamID
+	})
+
+	return drivers, teams
+}
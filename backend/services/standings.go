@@ -0,0 +1,243 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/f1-analytics/models"
+)
+
+// SeasonResults bundles the races of a season with their stored results
+type SeasonResults struct {
+	Season  int
+	Races   []models.Race                // Ordered by round
+	Results map[uint][]models.RaceDriver // Keyed by race ID
+}
+
+// DriverStanding is a driver's championship position after a given round
+type DriverStanding struct {
+	Position      int     `json:"position"`
+	DriverID      uint    `json:"driver_id"`
+	Points        float64 `json:"points"`
+	Wins          int     `json:"wins"`
+	Podiums       int     `json:"podiums"`
+	PositionDelta int     `json:"position_delta"` // Places gained since the previous round
+}
+
+// ConstructorStanding is a team's championship position after a given round
+type ConstructorStanding struct {
+	Position      int             `json:"position"`
+	TeamID        uint            `json:"team_id"`
+	Points        float64         `json:"points"`
+	Wins          int             `json:"wins"`
+	Podiums       int             `json:"podiums"`
+	PositionDelta int             `json:"position_delta"` // Places gained since the previous round
+	Progression   []RoundStanding `json:"progression"`
+}
+
+// RoundStanding is a snapshot of a championship entry after one round
+type RoundStanding struct {
+	Round    int     `json:"round"`
+	Points   float64 `json:"points"`
+	Position int     `json:"position"`
+}
+
+// tableEntry is one row of a championship table while it is being totalled
+type tableEntry struct {
+	id       uint
+	points   float64
+	wins     int
+	podiums  int
+	finishes map[int]int // finishes[p] counts Grand Prix finishes in position p, used for countback
+}
+
+// LastRound returns the highest round that has results stored
+func (s SeasonResults) LastRound() int {
+	last := 0
+	for _, race := range s.Races {
+		if len(s.Results[race.ID]) > 0 && race.Round > last {
+			last = race.Round
+		}
+	}
+	return last
+}
+
+// TeamOf returns the team a driver raced for in a race, or zero when the driver
+// has no result there
+func (s SeasonResults) TeamOf(raceID, driverID uint) uint {
+	for _, result := range s.Results[raceID] {
+		if result.DriverID == driverID {
+			return result.TeamID
+		}
+	}
+	return 0
+}
+
+// ComputeDriverStandings builds the drivers' championship after the given round
+// from race and sprint points. A round of zero means the latest round with results.
+func ComputeDriverStandings(season SeasonResults, afterRound int) []DriverStanding {
+	if afterRound <= 0 {
+		afterRound = season.LastRound()
+	}
+
+	byDriver := func(result models.RaceDriver) uint { return result.DriverID }
+	table := tally(season, afterRound, byDriver)
+	previous := positions(tally(season, season.previousRound(afterRound), byDriver))
+
+	standings := make([]DriverStanding, len(table))
+	for i, entry := range table {
+		standings[i] = DriverStanding{
+			Position: i + 1,
+			DriverID: entry.id,
+			Points:   entry.points,
+			Wins:     entry.wins,
+			Podiums:  entry.podiums,
+		}
+		if pos, ok := previous[entry.id]; ok {
+			standings[i].PositionDelta = pos - standings[i].Position
+		}
+	}
+
+	return standings
+}
+
+// ComputeConstructorStandings builds the constructors' championship after the
+// given round. Points follow the team a driver raced for in each round, so a
+// mid-season move splits the driver's points between both teams.
+func ComputeConstructorStandings(season SeasonResults, afterRound int) []ConstructorStanding {
+	if afterRound <= 0 {
+		afterRound = season.LastRound()
+	}
+
+	byTeam := func(result models.RaceDriver) uint { return result.TeamID }
+	table := tally(season, afterRound, byTeam)
+	previous := positions(tally(season, season.previousRound(afterRound), byTeam))
+
+	standings := make([]ConstructorStanding, len(table))
+	index := make(map[uint]int, len(table))
+	for i, entry := range table {
+		standings[i] = ConstructorStanding{
+			Position: i + 1,
+			TeamID:   entry.id,
+			Points:   entry.points,
+			Wins:     entry.wins,
+			Podiums:  entry.podiums,
+		}
+		if pos, ok := previous[entry.id]; ok {
+			standings[i].PositionDelta = pos - standings[i].Position
+		}
+		index[entry.id] = i
+	}
+
+	// Replay the season round by round for the progression chart
+	for _, race := range season.Races {
+		if race.Round > afterRound || len(season.Results[race.ID]) == 0 {
+			continue
+		}
+		for pos, entry := range tally(season, race.Round, byTeam) {
+			if i, ok := index[entry.id]; ok {
+				standings[i].Progression = append(standings[i].Progression, RoundStanding{
+					Round:    race.Round,
+					Points:   entry.points,
+					Position: pos + 1,
+				})
+			}
+		}
+	}
+
+	return standings
+}
+
+// previousRound returns the last round with results before the given one, or zero
+func (s SeasonResults) previousRound(round int) int {
+	previous := 0
+	for _, race := range s.Races {
+		if race.Round < round && race.Round > previous && len(s.Results[race.ID]) > 0 {
+			previous = race.Round
+		}
+	}
+	return previous
+}
+
+// tally totals race and sprint points up to and including the given round,
+// grouping results by the supplied key, and returns the entries in championship
+// order
+func tally(season SeasonResults, afterRound int, key func(models.RaceDriver) uint) []tableEntry {
+	if afterRound <= 0 {
+		return nil
+	}
+
+	entries := make(map[uint]*tableEntry)
+	for _, race := range season.Races {
+		if race.Round > afterRound {
+			continue
+		}
+		for _, result := range season.Results[race.ID] {
+			id := key(result)
+			if id == 0 {
+				continue
+			}
+			entry, ok := entries[id]
+			if !ok {
+				entry = &tableEntry{id: id, finishes: make(map[int]int)}
+				entries[id] = entry
+			}
+			entry.points += result.Points + result.SprintPoints
+			if result.Position > 0 {
+				entry.finishes[result.Position]++
+			}
+			if result.Position == 1 {
+				entry.wins++
+			}
+			if result.Position >= 1 && result.Position <= 3 {
+				entry.podiums++
+			}
+		}
+	}
+
+	table := make([]tableEntry, 0, len(entries))
+	for _, entry := range entries {
+		table = append(table, *entry)
+	}
+	sort.Slice(table, func(i, j int) bool {
+		return ranksAhead(table[i], table[j])
+	})
+
+	return table
+}
+
+// positions maps each entry of an ordered table to its championship position
+func positions(table []tableEntry) map[uint]int {
+	byID := make(map[uint]int, len(table))
+	for i, entry := range table {
+		byID[entry.id] = i + 1
+	}
+	return byID
+}
+
+// ranksAhead reports whether entry a is classified ahead of entry b. Ties on
+// points are broken by countback: most wins, then most second places, and so on.
+func ranksAhead(a, b tableEntry) bool {
+	if a.points != b.points {
+		return a.points > b.points
+	}
+
+	maxPosition := 0
+	for pos := range a.finishes {
+		if pos > maxPosition {
+			maxPosition = pos
+		}
+	}
+	for pos := range b.finishes {
+		if pos > maxPosition {
+			maxPosition = pos
+		}
+	}
+	for pos := 1; pos <= maxPosition; pos++ {
+		if a.finishes[pos] != b.finishes[pos] {
+			return a.finishes[pos] > b.finishes[pos]
+		}
+	}
+
+	// Still level, keep the order stable
+	return a.id < b.id
+}
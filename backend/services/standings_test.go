@@ -0,0 +1,54 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/f1-analytics/models"
+)
+
+// levelSeason is a season in which every driver ends on the same points,
+// finishing in the given positions round by round
+func levelSeason(finishes map[uint][]int) SeasonResults {
+	season := SeasonResults{Season: 2024, Results: make(map[uint][]models.RaceDriver)}
+	for round := 1; round <= 2; round++ {
+		race := models.Race{Season: 2024, Round: round}
+		race.ID = uint(round)
+		season.Races = append(season.Races, race)
+		for driverID, positions := range finishes {
+			season.Results[race.ID] = append(season.Results[race.ID], models.RaceDriver{
+				DriverID: driverID,
+				RaceID:   race.ID,
+				TeamID:   driverID,
+				Position: positions[round-1],
+				Points:   10,
+			})
+		}
+	}
+	return season
+}
+
+func TestStandingsCountback(t *testing.T) {
+	tests := []struct {
+		name     string
+		finishes map[uint][]int
+		want     []uint
+	}{
+		{"a win beats more second places", map[uint][]int{1: {2, 2}, 2: {1, 10}}, []uint{2, 1}},
+		{"level on wins, the next best finishes decide", map[uint][]int{1: {1, 4}, 2: {3, 1}}, []uint{2, 1}},
+		{"identical records keep driver order", map[uint][]int{2: {1, 5}, 1: {5, 1}}, []uint{1, 2}},
+	}
+	for _, tt := range tests {
+		season := levelSeason(tt.finishes)
+
+		drivers := ComputeDriverStandings(season, 0)
+		constructors := ComputeConstructorStandings(season, 0)
+		for i, id := range tt.want {
+			if drivers[i].DriverID != id {
+				t.Errorf("%s: driver P%d is %d, want %d", tt.name, i+1, drivers[i].DriverID, id)
+			}
+			if constructors[i].TeamID != id {
+				t.Errorf("%s: constructor P%d is %d, want %d", tt.name, i+1, constructors[i].TeamID, id)
+			}
+		}
+	}
+}
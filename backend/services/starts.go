@@ -0,0 +1,191 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/f1-analytics/models"
+)
+
+// Sides of the grid. Odd slots, pole among them, line up on one side and even
+// slots on the other.
+const (
+	GridSideOdd  = "odd"
+	GridSideEven = "even"
+)
+
+// StartRace is what a race contributes to the start analysis
+type StartRace struct {
+	RaceID      uint
+	Round       int
+	Results     []models.RaceDriver
+	LapOne      map[uint]int // Driver's position at the end of the first lap
+	LapsStarted map[uint]int // Highest lap each driver started, empty when no laps are stored
+}
+
+// StartStats totals a set of starts. Gains compare the grid slot with the
+// position at the end of the first lap; positive is places gained.
+type StartStats struct {
+	Starts          int     `json:"starts"`
+	PositionsGained int     `json:"positions_gained"`
+	PositionsLost   int     `json:"positions_lost"`
+	NetGain         int     `json:"net_gain"`
+	MeanGain        float64 `json:"mean_gain"`
+	Improved        int     `json:"improved"`
+	Held            int     `json:"held"`
+	Dropped         int     `json:"dropped"`
+	BestGain        int     `json:"best_gain"`
+	WorstGain       int     `json:"worst_gain"`
+	RetiredOnLapOne int     `json:"retired_on_lap_one"` // Not counted in the starts above
+}
+
+// DriverStart is how one driver's start went
+type DriverStart struct {
+	RaceID         uint   `json:"race_id"`
+	Round          int    `json:"round"`
+	Grid           int    `json:"grid"`
+	Side           string `json:"side"`
+	LapOnePosition int    `json:"lap_one_position"`
+	Gain           int    `json:"gain"`
+}
+
+// DriverStarts is a driver's start record over a season
+type DriverStarts struct {
+	DriverID uint `json:"driver_id"`
+	StartStats
+	OddSide  StartStats    `json:"odd_side"`
+	EvenSide StartStats    `json:"even_side"`
+	Races    []DriverStart `json:"races"`
+}
+
+// GridSlotStarts is how starts from one grid slot went across the field
+type GridSlotStarts struct {
+	Grid int    `json:"grid"`
+	Side string `json:"side"`
+	StartStats
+}
+
+// SeasonStarts is the start analysis of a season
+type SeasonStarts struct {
+	Races    int              `json:"races"`
+	Overall  StartStats       `json:"overall"`
+	OddSide  StartStats       `json:"odd_side"`
+	EvenSide StartStats       `json:"even_side"`
+	Slots    []GridSlotStarts `json:"slots"`
+	Drivers  []DriverStarts   `json:"drivers"`
+}
+
+// GridSide returns the side of the grid a slot is on
+func GridSide(grid int) string {
+	if grid%2 == 1 {
+		return GridSideOdd
+	}
+	return GridSideEven
+}
+
+// add counts one start
+func (s *StartStats) add(gain int) {
+	if s.Starts == 0 || gain > s.BestGain {
+		s.BestGain = gain
+	}
+	if s.Starts == 0 || gain < s.WorstGain {
+		s.WorstGain = gain
+	}
+	s.Starts++
+	s.NetGain += gain
+	switch {
+	case gain > 0:
+		s.Improved++
+		s.PositionsGained += gain
+	case gain < 0:
+		s.Dropped++
+		s.PositionsLost -= gain
+	default:
+		s.Held++
+	}
+	s.MeanGain = float64(s.NetGain) / float64(s.Starts)
+}
+
+// AnalyseStarts compares every driver's grid slot with their position at the
+// end of the first lap. Pit lane starters have no slot and are left out, as
+// are races without first lap positions. Retired drivers whose laps show they
+// never started a second lap count as lap one retirements.
+func AnalyseStarts(races []StartRace) SeasonStarts {
+	var season SeasonStarts
+	drivers := make(map[uint]*DriverStarts)
+	slots := make(map[int]*GridSlotStarts)
+
+	for _, race := range races {
+		if len(race.LapOne) == 0 {
+			continue
+		}
+		season.Races++
+		for _, result := range race.Results {
+			if result.Grid <= 0 {
+				continue
+			}
+			status := ClassifyStatus(result.Status)
+			if !status.Started() {
+				continue
+			}
+			side := GridSide(result.Grid)
+
+			driver, ok := drivers[result.DriverID]
+			if !ok {
+				driver = &DriverStarts{DriverID: result.DriverID, Races: []DriverStart{}}
+				drivers[result.DriverID] = driver
+			}
+			driverSide, seasonSide := &driver.OddSide, &season.OddSide
+			if side == GridSideEven {
+				driverSide, seasonSide = &driver.EvenSide, &season.EvenSide
+			}
+			slot, ok := slots[result.Grid]
+			if !ok {
+				slot = &GridSlotStarts{Grid: result.Grid, Side: side}
+				slots[result.Grid] = slot
+			}
+
+			if status.Retired() && len(race.LapsStarted) > 0 && race.LapsStarted[result.DriverID] <= 1 {
+				for _, stats := range []*StartStats{&season.Overall, seasonSide, &slot.StartStats, &driver.StartStats, driverSide} {
+					stats.RetiredOnLapOne++
+				}
+				continue
+			}
+			position, ok := race.LapOne[result.DriverID]
+			if !ok || position <= 0 {
+				continue
+			}
+
+			gain := result.Grid - position
+			for _, stats := range []*StartStats{&season.Overall, seasonSide, &slot.StartStats, &driver.StartStats, driverSide} {
+				stats.add(gain)
+			}
+			driver.Races = append(driver.Races, DriverStart{
+				RaceID:         race.RaceID,
+				Round:          race.Round,
+				Grid:           result.Grid,
+				Side:           side,
+				LapOnePosition: position,
+				Gain:           gain,
+			})
+		}
+	}
+
+	season.Slots = make([]GridSlotStarts, 0, len(slots))
+	for _, slot := range slots {
+		season.Slots = append(season.Slots, *slot)
+	}
+	sort.Slice(season.Slots, func(i, j int) bool { return season.Slots[i].Grid < season.Slots[j].Grid })
+
+	season.Drivers = make([]DriverStarts, 0, len(drivers))
+	for _, driver := range drivers {
+		sort.Slice(driver.Races, func(i, j int) bool { return driver.Races[i].Round < driver.Races[j].Round })
+		season.Drivers = append(season.Drivers, *driver)
+	}
+	sort.Slice(season.Drivers, func(i, j int) bool {
+		if season.Drivers[i].MeanGain != season.Drivers[j].MeanGain {
+			return season.Drivers[i].MeanGain > season.Drivers[j].MeanGain
+		}
+		return season.Drivers[i].DriverID < season.Drivers[j].DriverID
+	})
+	return season
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/f1-analytics/models"
+)
+
+func TestAnalyseStartsLapOneRetirements(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      string
+		lapsStarted map[uint]int
+		lapOne      int
+		wantStarts  int
+		wantRetired int
+	}{
+		{"finisher", "Finished", map[uint]int{1: 57}, 2, 1, 0},
+		{"retired before lap two", "Accident", map[uint]int{1: 1}, 0, 0, 1},
+		{"retired later in the race", "Engine", map[uint]int{1: 30}, 2, 1, 0},
+		{"retired without stored laps", "Retired", nil, 2, 1, 0},
+		{"retired without laps or a lap one position", "Retired", nil, 0, 0, 0},
+		{"did not start", "Did not start", map[uint]int{}, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		lapOne := map[uint]int{2: 1}
+		if tt.lapOne > 0 {
+			lapOne[1] = tt.lapOne
+		}
+		starts := AnalyseStarts([]StartRace{{
+			RaceID: 1,
+			Round:  1,
+			Results: []models.RaceDriver{
+				{DriverID: 1, RaceID: 1, Grid: 4, Status: tt.status},
+				{DriverID: 2, RaceID: 1, Grid: 1, Status: "Finished"},
+			},
+			LapOne:      lapOne,
+			LapsStarted: tt.lapsStarted,
+		}})
+
+		var driver DriverStarts
+		for _, d := range starts.Drivers {
+			if d.DriverID == 1 {
+				driver = d
+			}
+		}
+		if driver.Starts != tt.wantStarts || driver.RetiredOnLapOne != tt.wantRetired {
+			t.Errorf("%s: %d starts and %d lap one retirements, want %d and %d",
+				tt.name, driver.Starts, driver.RetiredOnLapOne, tt.wantStarts, tt.wantRetired)
+		}
+		if tt.wantStarts == 1 && driver.MeanGain != 2 {
+			t.Errorf("%s: mean gain %v, want 2", tt.name, driver.MeanGain)
+		}
+	}
+}
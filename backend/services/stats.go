@@ -0,0 +1,121 @@
+package services
+
+import "sort"
+
+// StatEntry is one race weekend's result as seen by the statistics builder
+type StatEntry struct {
+	Season     int
+	Round      int
+	CircuitID  uint
+	Circuit    string
+	Position   int
+	Points     float64 // Race and sprint points combined
+	Status     string
+	Pole       bool
+	FastestLap bool
+}
+
+// CareerStats are the headline statistics over a set of results
+type CareerStats struct {
+	Starts      int     `json:"starts"`
+	Wins        int     `json:"wins"`
+	Podiums     int     `json:"podiums"`
+	Poles       int     `json:"poles"`
+	FastestLaps int     `json:"fastestLaps"`
+	Points      float64 `json:"points"`
+	DNFs        int     `json:"dnfs"`
+	BestFinish  int     `json:"bestFinish"`
+}
+
+// SeasonStats are the statistics of a single season
+type SeasonStats struct {
+	Season int `json:"season"`
+	CareerStats
+}
+
+// CircuitStats are the statistics at a single circuit
+type CircuitStats struct {
+	CircuitID uint   `json:"circuitId"`
+	Circuit   string `json:"circuit"`
+	CareerStats
+}
+
+// StatsBreakdown holds the totals together with their per-season and
+// per-circuit splits
+type StatsBreakdown struct {
+	CareerStats
+	BySeason  []SeasonStats  `json:"bySeason"`
+	ByCircuit []CircuitStats `json:"byCircuit"`
+}
+
+// add folds one result into the totals
+func (s *CareerStats) add(entry StatEntry) {
+	status := ClassifyStatus(entry.Status)
+	if !status.Started() {
+		return
+	}
+
+	s.Starts++
+	s.Points += entry.Points
+	if entry.Position == 1 {
+		s.Wins++
+	}
+	if entry.Position >= 1 && entry.Position <= 3 {
+		s.Podiums++
+	}
+	if entry.Pole {
+		s.Poles++
+	}
+	if entry.FastestLap {
+		s.FastestLaps++
+	}
+	if entry.Position > 0 && (s.BestFinish == 0 || entry.Position < s.BestFinish) {
+		s.BestFinish = entry.Position
+	}
+	if status.Retired() {
+		s.DNFs++
+	}
+}
+
+// ComputeStats totals the entries and breaks them down by season and by circuit
+func ComputeStats(entries []StatEntry) StatsBreakdown {
+	var breakdown StatsBreakdown
+	bySeason := make(map[int]*SeasonStats)
+	byCircuit := make(map[uint]*CircuitStats)
+
+	for _, entry := range entries {
+		breakdown.CareerStats.add(entry)
+
+		season, ok := bySeason[entry.Season]
+		if !ok {
+			season = &SeasonStats{Season: entry.Season}
+			bySeason[entry.Season] = season
+		}
+		season.add(entry)
+
+		circuit, ok := byCircuit[entry.CircuitID]
+		if !ok {
+			circuit = &CircuitStats{CircuitID: entry.CircuitID, Circuit: entry.Circuit}
+			byCircuit[entry.CircuitID] = circuit
+		}
+		circuit.add(entry)
+	}
+
+	breakdown.BySeason = make([]SeasonStats, 0, len(bySeason))
+	for _, season := range bySeason {
+		breakdown.BySeason = append(breakdown.BySeason, *season)
+	}
+	sort.Slice(breakdown.BySeason, func(i, j int) bool {
+		return breakdown.BySeason[i].Season < breakdown.BySeason[j].Season
+	})
+
+	breakdown.ByCircuit = make([]CircuitStats, 0, len(byCircuit))
+	for _, circuit := range byCircuit {
+		breakdown.ByCircuit = append(breakdown.ByCircuit, *circuit)
+	}
+	sort.Slice(breakdown.ByCircuit, func(i, j int) bool {
+		return breakdown.ByCircuit[i].Circuit < breakdown.ByCircuit[j].Circuit
+	})
+
+	return breakdown
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+)
+
+// StatusCategory is what a free-form result status boils down to
+type StatusCategory string
+
+const (
+	StatusFinished     StatusCategory = "finished"
+	StatusLapped       StatusCategory = "lapped" // Classified a lap or more down
+	StatusAccident     StatusCategory = "accident"
+	StatusCollision    StatusCategory = "collision"
+	StatusMechanical   StatusCategory = "mechanical"
+	StatusRetired      StatusCategory = "retired" // Retired without a recorded cause
+	StatusDisqualified StatusCategory = "disqualified"
+	StatusDNS          StatusCategory = "dns"
+)
+
+// Subtypes of a mechanical retirement
+const (
+	MechanicalPowerUnit  = "power_unit"
+	MechanicalGearbox    = "gearbox"
+	MechanicalHydraulics = "hydraulics"
+	MechanicalCooling    = "cooling"
+	MechanicalFuel       = "fuel"
+	MechanicalElectrical = "electrical"
+	MechanicalBrakes     = "brakes"
+	MechanicalSuspension = "suspension"
+	MechanicalWheel      = "wheel"
+	MechanicalOther      = "other"
+)
+
+// StatusClass is a result status placed in the taxonomy
+type StatusClass struct {
+	Category StatusCategory `json:"category"`
+	Subtype  string         `json:"subtype,omitempty"` // Set for mechanical retirements only
+}
+
+var lappedStatus = regexp.MustCompile(`^\+\s*\d+\s+laps?$`)
+
+// Keywords for each category, checked in order so that, say, "Collision
+// damage" is a collision rather than mechanical damage
+var (
+	dnsKeywords          = []string{"did not start", "withdrew", "withdrawn", "did not qualify", "not started", "107%"}
+	disqualifiedKeywords = []string{"disqualified", "excluded"}
+	collisionKeywords    = []string{"collision", "contact"}
+	accidentKeywords     = []string{"accident", "crash", "spun off", "spin", "damage", "debris"}
+	mechanicalSubtypes   = []struct {
+		subtype  string
+		keywords []string
+	}{
+		{MechanicalPowerUnit, []string{"engine", "power unit", "turbo", "mgu", "ers", "energy store", "battery", "power loss", "exhaust"}},
+		{MechanicalGearbox, []string{"gearbox", "transmission", "clutch", "driveshaft", "differential"}},
+		{MechanicalHydraulics, []string{"hydraulic"}},
+		{MechanicalCooling, []string{"overheating", "water", "oil", "radiator", "cooling"}},
+		{MechanicalFuel, []string{"fuel"}},
+		{MechanicalElectrical, []string{"electrical", "electronics", "electric"}},
+		{MechanicalBrakes, []string{"brake"}},
+		{MechanicalSuspension, []string{"suspension", "steering", "damper"}},
+		{MechanicalWheel, []string{"wheel", "tyre", "puncture"}},
+		{MechanicalOther, []string{"mechanical", "technical", "throttle", "pneumatic", "vibration", "chassis", "wing"}},
+	}
+)
+
+// ClassifyStatus places a result status in the taxonomy. An empty status is
+// a finish, as results ingested without one came from classified drivers;
+// statuses that say nothing of the cause, such as "DNF", are plain
+// retirements.
+func ClassifyStatus(status string) StatusClass {
+	s := strings.ToLower(strings.TrimSpace(status))
+	switch {
+	case s == "" || s == "finished":
+		return StatusClass{Category: StatusFinished}
+	case s == "lapped" || lappedStatus.MatchString(s):
+		return StatusClass{Category: StatusLapped}
+	case strings.HasPrefix(s, "+"):
+		// A gap to the winner
+		return StatusClass{Category: StatusFinished}
+	case s == "dns" || containsAny(s, dnsKeywords):
+		return StatusClass{Category: StatusDNS}
+	case s == "dsq" || containsAny(s, disqualifiedKeywords):
+		return StatusClass{Category: StatusDisqualified}
+	case containsAny(s, collisionKeywords):
+		return StatusClass{Category: StatusCollision}
+	case containsAny(s, accidentKeywords):
+		return StatusClass{Category: StatusAccident}
+	}
+	for _, mechanical := range mechanicalSubtypes {
+		if containsAny(s, mechanical.keywords) {
+			return StatusClass{Category: StatusMechanical, Subtype: mechanical.subtype}
+		}
+	}
+	return StatusClass{Category: StatusRetired}
+}
+
+// Started reports whether the car took the start
+func (c StatusClass) Started() bool {
+	return c.Category != StatusDNS
+}
+
+// Finished reports whether the car was classified at the end, lapped or not
+func (c StatusClass) Finished() bool {
+	return c.Category == StatusFinished || c.Category == StatusLapped
+}
+
+// Retired reports whether the car started but stopped before the end.
+// Disqualifications are not retirements.
+func (c StatusClass) Retired() bool {
+	switch c.Category {
+	case StatusAccident, StatusCollision, StatusMechanical, StatusRetired:
+		return true
+	}
+	return false
+}
+
+// containsAny reports whether s holds any of the keywords. Keywords of three
+// letters or fewer must be whole words, so "ers" does not match "dampers".
+func containsAny(s string, keywords []string) bool {
+	words := strings.FieldsFunc(s, func(r rune) bool { return r < 'a' || r > 'z' })
+	for _, keyword := range keywords {
+		if len(keyword) > 3 {
+			if strings.Contains(s, keyword) {
+				return true
+			}
+			continue
+		}
+		for _, word := range words {
+			if word == keyword {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package services
+
+import "testing"
+
+func TestClassifyStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		want   StatusClass
+	}{
+		{"", StatusClass{Category: StatusFinished}},
+		{"Finished", StatusClass{Category: StatusFinished}},
+		{"+12.345s", StatusClass{Category: StatusFinished}},
+		{"+1 Lap", StatusClass{Category: StatusLapped}},
+		{"+3 Laps", StatusClass{Category: StatusLapped}},
+		{"Lapped", StatusClass{Category: StatusLapped}},
+		{"Did not start", StatusClass{Category: StatusDNS}},
+		{"DNS", StatusClass{Category: StatusDNS}},
+		{"Withdrew", StatusClass{Category: StatusDNS}},
+		{"Disqualified", StatusClass{Category: StatusDisqualified}},
+		{"DSQ", StatusClass{Category: StatusDisqualified}},
+		{"Collision damage", StatusClass{Category: StatusCollision}},
+		{"Accident", StatusClass{Category: StatusAccident}},
+		{"Spun off", StatusClass{Category: StatusAccident}},
+		{"Engine", StatusClass{Category: StatusMechanical, Subtype: MechanicalPowerUnit}},
+		{"ERS", StatusClass{Category: StatusMechanical, Subtype: MechanicalPowerUnit}},
+		{"Gearbox", StatusClass{Category: StatusMechanical, Subtype: MechanicalGearbox}},
+		{"Hydraulics", StatusClass{Category: StatusMechanical, Subtype: MechanicalHydraulics}},
+		{"Water pressure", StatusClass{Category: StatusMechanical, Subtype: MechanicalCooling}},
+		{"Dampers", StatusClass{Category: StatusMechanical, Subtype: MechanicalSuspension}},
+		{"Puncture", StatusClass{Category: StatusMechanical, Subtype: MechanicalWheel}},
+		{"Retired", StatusClass{Category: StatusRetired}},
+		{"DNF", StatusClass{Category: StatusRetired}},
+	}
+	for _, tt := range tests {
+		if got := ClassifyStatus(tt.status); got != tt.want {
+			t.Errorf("ClassifyStatus(%q) = %+v, want %+v", tt.status, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/f1-analytics/models"
+)
+
+// CompoundParams describe how a tyre compound performs over a stint
+type CompoundParams struct {
+	BaseOffset float64 `json:"base_offset"` // Seconds per lap relative to the base lap time on new tyres
+	DegRate    float64 `json:"deg_rate"`    // Seconds lost per lap of tyre age
+	CliffAge   int     `json:"cliff_age"`   // Tyre age at which the cliff starts, zero for none
+	CliffRate  float64 `json:"cliff_rate"`  // Extra seconds lost per lap beyond the cliff
+}
+
+// StrategyStint is one planned stint
+type StrategyStint struct {
+	Compound string `json:"compound"`
+	Laps     int    `json:"laps"`     // Zero on the final stint runs it to the flag
+	TyreAge  int    `json:"tyre_age"` // Laps already on the set when fitted
+}
+
+// StrategyPlan is a candidate sequence of stints
+type StrategyPlan struct {
+	Name   string          `json:"name"`
+	Stints []StrategyStint `json:"stints"`
+}
+
+// StrategyInput is everything the simulator needs for one race
+type StrategyInput struct {
+	TotalLaps   int                       `json:"total_laps"`
+	PitLoss     float64                   `json:"pit_loss"`      // Seconds lost to a stop, including the pit lane
+	BaseLapTime float64                   `json:"base_lap_time"` // Empty-tank lap time on new tyres of the quickest compound
+	Fuel        *FuelCorrection           `json:"fuel,omitempty"`
+	Compounds   map[string]CompoundParams `json:"compounds"`
+	Plans       []StrategyPlan            `json:"plans"`
+}
+
+// PlanResult is the simulated outcome of one plan
+type PlanResult struct {
+	Rank        int       `json:"rank"`
+	Name        string    `json:"name"`
+	TotalTime   float64   `json:"total_time"`
+	Gap         float64   `json:"gap"` // To the best plan at the flag
+	Stops       int       `json:"stops"`
+	PitLaps     []int     `json:"pit_laps"`
+	LapTimes    []float64 `json:"lap_times"`
+	GapToLeader []float64 `json:"gap_to_leader"` // After each lap, to whichever plan was ahead then
+}
+
+// SimulateStrategies runs every plan lap by lap and ranks them by total race time
+func SimulateStrategies(input StrategyInput) ([]PlanResult, error) {
+	if input.TotalLaps <= 0 {
+		return nil, fmt.Errorf("total laps must be positive")
+	}
+	if input.BaseLapTime <= 0 {
+		return nil, fmt.Errorf("base lap time must be positive")
+	}
+	if len(input.Plans) == 0 {
+		return nil, fmt.Errorf("at least one plan is required")
+	}
+
+	results := make([]PlanResult, len(input.Plans))
+	for i, plan := range input.Plans {
+		result, err := simulatePlan(input, plan)
+		if err != nil {
+			return nil, fmt.Errorf("plan %q: %w", plan.Name, err)
+		}
+		results[i] = result
+	}
+
+	// Gaps after every lap, against whichever plan was leading at that point
+	cumulative := make([][]float64, len(results))
+	for i, result := range results {
+		cumulative[i] = make([]float64, input.TotalLaps)
+		total := 0.0
+		for lap, t := range result.LapTimes {
+			total += t
+			cumulative[i][lap] = total
+		}
+	}
+	for lap := 0; lap < input.TotalLaps; lap++ {
+		leader := cumulative[0][lap]
+		for i := range results {
+			if cumulative[i][lap] < leader {
+				leader = cumulative[i][lap]
+			}
+		}
+		for i := range results {
+			results[i].GapToLeader = append(results[i].GapToLeader, cumulative[i][lap]-leader)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].TotalTime < results[j].TotalTime })
+	for i := range results {
+		results[i].Rank = i + 1
+		results[i].Gap = results[i].TotalTime - results[0].TotalTime
+	}
+
+	return results, nil
+}
+
+// simulatePlan builds the lap times of a single plan
+func simulatePlan(input StrategyInput, plan StrategyPlan) (PlanResult, error) {
+	if len(plan.Stints) == 0 {
+		return PlanResult{}, fmt.Errorf("no stints")
+	}
+
+	fuel := input.Fuel
+	if fuel == nil {
+		fuel = &FuelCorrection{KgPerLap: DefaultFuelKgPerLap, SecondsPerKg: DefaultSecondsPerKg}
+	}
+
+	result := PlanResult{Name: plan.Name, Stops: len(plan.Stints) - 1}
+	lap := 1
+	for i, stint := range plan.Stints {
+		params, ok := input.Compounds[strings.ToUpper(stint.Compound)]
+		if !ok {
+			return PlanResult{}, fmt.Errorf("no parameters for compound %s", stint.Compound)
+		}
+
+		length := stint.Laps
+		last := i == len(plan.Stints)-1
+		if last && length == 0 {
+			length = input.TotalLaps - lap + 1
+		}
+		if length <= 0 {
+			return PlanResult{}, fmt.Errorf("stint %d has no laps", i+1)
+		}
+
+		for age := stint.TyreAge; age < stint.TyreAge+length; age++ {
+			remaining := float64(input.TotalLaps-lap+1) * fuel.KgPerLap
+			t := input.BaseLapTime + params.BaseOffset + params.DegRate*float64(age) + remaining*fuel.SecondsPerKg
+			if params.CliffAge > 0 && age > params.CliffAge {
+				t += params.CliffRate * float64(age-params.CliffAge)
+			}
+			if !last && age == stint.TyreAge+length-1 {
+				t += input.PitLoss
+				result.PitLaps = append(result.PitLaps, lap)
+			}
+			result.LapTimes = append(result.LapTimes, t)
+			result.TotalTime += t
+			lap++
+		}
+	}
+
+	if lap-1 != input.TotalLaps {
+		return PlanResult{}, fmt.Errorf("stints cover %d laps, race is %d", lap-1, input.TotalLaps)
+	}
+	return result, nil
+}
+
+// StrategyDefaults turns historical degradation fits into simulator parameters:
+// the quickest compound's fitted new-tyre pace becomes the base lap time and
+// the others are expressed as offsets from it
+func StrategyDefaults(stints []StintDegradation) (float64, map[string]CompoundParams) {
+	compounds := AggregateCompounds(stints)
+	if len(compounds) == 0 {
+		return 0, nil
+	}
+
+	base := 0.0
+	for _, compound := range compounds {
+		if compound.MedianBasePace > 0 && (base == 0 || compound.MedianBasePace < base) {
+			base = compound.MedianBasePace
+		}
+	}
+
+	cliffs := make(map[string][]float64)
+	for _, stint := range stints {
+		if stint.CliffTyreAge > 0 {
+			cliffs[stint.Compound] = append(cliffs[stint.Compound], float64(stint.CliffTyreAge))
+		}
+	}
+
+	params := make(map[string]CompoundParams, len(compounds))
+	for _, compound := range compounds {
+		p := CompoundParams{
+			BaseOffset: compound.MedianBasePace - base,
+			DegRate:    compound.MedianDegRate,
+		}
+		if ages := cliffs[compound.Compound]; len(ages) > 0 {
+			p.CliffAge = int(Median(ages))
+			p.CliffRate = CliffSlopeIncrease
+		}
+		params[strings.ToUpper(compound.Compound)] = p
+	}
+	return base, params
+}
+
+// EstimatePitLoss returns the time lost to each pit stop in a race: the in-lap
+// and out-lap together, less two of the driver's median representative laps
+func EstimatePitLoss(laps []models.Lap) []float64 {
+	clean, _ := RepresentativeLaps(laps, PaceOptions{})
+	byDriver := make(map[uint]map[int]models.Lap)
+	for _, lap := range laps {
+		if byDriver[lap.DriverID] == nil {
+			byDriver[lap.DriverID] = make(map[int]models.Lap)
+		}
+		byDriver[lap.DriverID][lap.LapNumber] = lap
+	}
+
+	var losses []float64
+	for driverID, driverLaps := range byDriver {
+		reference := Median(clean[driverID])
+		if reference == 0 {
+			continue
+		}
+		for number, inLap := range driverLaps {
+			if !inLap.PitStop || inLap.SafetyCar || inLap.VirtualSafetyCar {
+				continue
+			}
+			outLap, ok := driverLaps[number+1]
+			if !ok || inLap.LapTime <= 0 || outLap.LapTime <= 0 || outLap.SafetyCar || outLap.VirtualSafetyCar {
+				continue
+			}
+			loss := inLap.LapTime.Seconds() + outLap.LapTime.Seconds() - 2*reference
+			if loss > 0 {
+				losses = append(losses, loss)
+			}
+		}
+	}
+	return losses
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/f1-analytics/models"
+)
+
+// TeammateRound holds one team's results and qualifying for a single round
+type TeammateRound struct {
+	Round      int
+	Results    []models.RaceDriver // Only the team's own drivers
+	Qualifying map[uint]models.QualifyingResult
+}
+
+// TeammatePairing summarises the battle between two drivers who shared a car
+type TeammatePairing struct {
+	DriverA                uint       `json:"driver_a_id"`
+	DriverB                uint       `json:"driver_b_id"`
+	Rounds                 int        `json:"rounds"`
+	Qualifying             HeadToHead `json:"qualifying"`
+	AverageQualiGapMs      float64    `json:"average_quali_gap_ms"`      // A minus B, negative when A was quicker
+	AverageQualiGapPercent float64    `json:"average_quali_gap_percent"` // Relative to B's lap time
+	QualiGapSamples        int        `json:"quali_gap_samples"`
+	Race                   HeadToHead `json:"race"` // Only rounds where both drivers finished
+	PointsA                float64    `json:"points_a"`
+	PointsB                float64    `json:"points_b"`
+	PointsShareA           float64    `json:"points_share_a"` // Percentage of the pair's points scored by A
+}
+
+type pairKey struct{ a, b uint }
+
+// TeammateBattles pairs up every two drivers who raced for the team in the
+// same round and summarises their qualifying and race battles
+func TeammateBattles(rounds []TeammateRound) []TeammatePairing {
+	pairings := make(map[pairKey]*TeammatePairing)
+	gapMs := make(map[pairKey]float64)
+	gapPercent := make(map[pairKey]float64)
+
+	for _, round := range rounds {
+		results := append([]models.RaceDriver(nil), round.Results...)
+		sort.Slice(results, func(i, j int) bool { return results[i].DriverID < results[j].DriverID })
+
+		for i := 0; i < len(results); i++ {
+			for j := i + 1; j < len(results); j++ {
+				a, b := results[i], results[j]
+				key := pairKey{a.DriverID, b.DriverID}
+				pairing, ok := pairings[key]
+				if !ok {
+					pairing = &TeammatePairing{DriverA: a.DriverID, DriverB: b.DriverID}
+					pairings[key] = pairing
+				}
+
+				pairing.Rounds++
+				pairing.PointsA += a.Points + a.SprintPoints
+				pairing.PointsB += b.Points + b.SprintPoints
+
+				qualiA, hasA := round.Qualifying[a.DriverID]
+				qualiB, hasB := round.Qualifying[b.DriverID]
+				placeA, placeB := a.Grid, b.Grid
+				if hasA && hasB {
+					placeA, placeB = qualiA.Position, qualiB.Position
+				}
+				if placeA > 0 && placeB > 0 {
+					if placeA < placeB {
+						pairing.Qualifying.A++
+					} else if placeB < placeA {
+						pairing.Qualifying.B++
+					}
+				}
+
+				if lapA, lapB := comparableQualifyingLaps(qualiA, qualiB); hasA && hasB && lapA > 0 && lapB > 0 {
+					gap := lapA - lapB
+					gapMs[key] += float64(gap.Milliseconds())
+					gapPercent[key] += gap.Seconds() / lapB.Seconds() * 100
+					pairing.QualiGapSamples++
+				}
+
+				if a.Position > 0 && b.Position > 0 && ClassifyStatus(a.Status).Finished() && ClassifyStatus(b.Status).Finished() {
+					if a.Position < b.Position {
+						pairing.Race.A++
+					} else {
+						pairing.Race.B++
+					}
+				}
+			}
+		}
+	}
+
+	battles := make([]TeammatePairing, 0, len(pairings))
+	for key, pairing := range pairings {
+		if pairing.QualiGapSamples > 0 {
+			pairing.AverageQualiGapMs = gapMs[key] / float64(pairing.QualiGapSamples)
+			pairing.AverageQualiGapPercent = gapPercent[key] / float64(pairing.QualiGapSamples)
+		}
+		if total := pairing.PointsA + pairing.PointsB; total > 0 {
+			pairing.PointsShareA = pairing.PointsA / total * 100
+		}
+		battles = append(battles, *pairing)
+	}
+	sort.Slice(battles, func(i, j int) bool {
+		if battles[i].Rounds != battles[j].Rounds {
+			return battles[i].Rounds > battles[j].Rounds
+		}
+		if battles[i].DriverA != battles[j].DriverA {
+			return battles[i].DriverA < battles[j].DriverA
+		}
+		return battles[i].DriverB < battles[j].DriverB
+	})
+
+	return battles
+}
+
+// comparableQualifyingLaps picks the laps from the last qualifying session
+// both drivers set a time in, so that a Q3 lap is never set against a Q1 lap
+func comparableQualifyingLaps(a, b models.QualifyingResult) (time.Duration, time.Duration) {
+	switch {
+	case a.Q3 > 0 && b.Q3 > 0:
+		return a.Q3, b.Q3
+	case a.Q2 > 0 && b.Q2 > 0:
+		return a.Q2, b.Q2
+	case a.Q1 > 0 && b.Q1 > 0:
+		return a.Q1, b.Q1
+	}
+	return 0, 0
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"math"
+	"sort"
+
+	"github.com/f1-analytics/models"
+)
+
+const (
+	// DefaultTraceStep is the distance, in metres, between points of a compared trace
+	DefaultTraceStep = 10.0
+	// minCornerSpacing is how far apart, in metres, two corner apexes must be
+	minCornerSpacing = 150.0
+	// minCornerSpeedDrop is how much slower than the approach, in km/h, an apex must be
+	minCornerSpeedDrop = 20.0
+)
+
+// TelemetryPoint is a telemetry sample placed on the lap by distance
+type TelemetryPoint struct {
+	Distance float64 `json:"distance"` // Metres from the start of the lap
+	Time     float64 `json:"time"`     // Seconds from the start of the lap
+	Speed    float64 `json:"speed"`
+	RPM      int     `json:"rpm"`
+	Gear     int     `json:"gear"`
+	Throttle float64 `json:"throttle"`
+	Brake    bool    `json:"brake"`
+	DRS      bool    `json:"drs"`
+}
+
+// ComparedPoint is both laps at the same distance
+type ComparedPoint struct {
+	Distance  float64 `json:"distance"`
+	SpeedA    float64 `json:"speed_a"`
+	SpeedB    float64 `json:"speed_b"`
+	ThrottleA float64 `json:"throttle_a"`
+	ThrottleB float64 `json:"throttle_b"`
+	BrakeA    bool    `json:"brake_a"`
+	BrakeB    bool    `json:"brake_b"`
+	GearA     int     `json:"gear_a"`
+	GearB     int     `json:"gear_b"`
+	Delta     float64 `json:"delta"` // B's time minus A's at this distance, positive while A is ahead
+}
+
+// CornerDelta is the time one lap gained on the other through a corner
+type CornerDelta struct {
+	Number    int     `json:"number"`
+	Apex      float64 `json:"apex"` // Metres from the start of the lap
+	Start     float64 `json:"start"`
+	End       float64 `json:"end"`
+	MinSpeedA float64 `json:"min_speed_a"`
+	MinSpeedB float64 `json:"min_speed_b"`
+	TimeDelta float64 `json:"time_delta"` // Positive when A gained
+}
+
+// LapComparison overlays two laps on a common distance axis
+type LapComparison struct {
+	LapLength float64         `json:"lap_length"`
+	LapTimeA  float64         `json:"lap_time_a"` // From the first sample to the last, so a little short of the timed lap
+	LapTimeB  float64         `json:"lap_time_b"`
+	Trace     []ComparedPoint `json:"trace"`
+	Corners   []CornerDelta   `json:"corners"`
+}
+
+// DistanceTrace places a lap's telemetry on the lap by integrating speed
+// over time, averaging the speeds at either end of each interval
+func DistanceTrace(samples []models.CarDataSample) []TelemetryPoint {
+	sorted := make([]models.CarDataSample, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+
+	points := make([]TelemetryPoint, len(sorted))
+	for i, sample := range sorted {
+		points[i] = TelemetryPoint{
+			Speed:    float64(sample.Speed),
+			RPM:      sample.RPM,
+			Gear:     sample.Gear,
+			Throttle: float64(sample.Throttle),
+			Brake:    sample.Brake > 0,
+			DRS:      sample.DRS >= 10,
+		}
+		if i == 0 {
+			continue
+		}
+		points[i].Time = sample.Date.Sub(sorted[0].Date).Seconds()
+		dt := points[i].Time - points[i-1].Time
+		points[i].Distance = points[i-1].Distance + (points[i-1].Speed+points[i].Speed)/2/3.6*dt
+	}
+	return points
+}
+
+// CompareLaps lines two laps up on distance. Integrated distances never quite
+// agree, so both laps are stretched to the mean of their lengths before
+// being resampled every step metres.
+func CompareLaps(a, b []TelemetryPoint, step float64) LapComparison {
+	if len(a) < 2 || len(b) < 2 || a[len(a)-1].Distance <= 0 || b[len(b)-1].Distance <= 0 {
+		return LapComparison{Trace: []ComparedPoint{}, Corners: []CornerDelta{}}
+	}
+	if step <= 0 {
+		step = DefaultTraceStep
+	}
+
+	lengthA := a[len(a)-1].Distance
+	lengthB := b[len(b)-1].Distance
+	length := (lengthA + lengthB) / 2
+	a = scaleDistance(a, length/lengthA)
+	b = scaleDistance(b, length/lengthB)
+
+	comparison := LapComparison{
+		LapLength: length,
+		LapTimeA:  a[len(a)-1].Time,
+		LapTimeB:  b[len(b)-1].Time,
+	}
+	for d := 0.0; d <= length; d += step {
+		pa := pointAt(a, d)
+		pb := pointAt(b, d)
+		comparison.Trace = append(comparison.Trace, ComparedPoint{
+			Distance:  d,
+			SpeedA:    pa.Speed,
+			SpeedB:    pb.Speed,
+			ThrottleA: pa.Throttle,
+			ThrottleB: pb.Throttle,
+			BrakeA:    pa.Brake,
+			BrakeB:    pb.Brake,
+			GearA:     pa.Gear,
+			GearB:     pb.Gear,
+			Delta:     pb.Time - pa.Time,
+		})
+	}
+	comparison.Corners = cornerDeltas(comparison.Trace)
+	return comparison
+}
+
+func scaleDistance(points []TelemetryPoint, factor float64) []TelemetryPoint {
+	scaled := make([]TelemetryPoint, len(points))
+	for i, p := range points {
+		p.Distance *= factor
+		scaled[i] = p
+	}
+	return scaled
+}
+
+// pointAt interpolates time, speed and throttle at a distance, holding gear,
+// brake and DRS from the sample before it
+func pointAt(points []TelemetryPoint, distance float64) TelemetryPoint {
+	i := sort.Search(len(points), func(i int) bool { return points[i].Distance >= distance })
+	if i == 0 {
+		return points[0]
+	}
+	if i == len(points) {
+		return points[len(points)-1]
+	}
+	before, after := points[i-1], points[i]
+	span := after.Distance - before.Distance
+	if span <= 0 {
+		return after
+	}
+	f := (distance - before.Distance) / span
+	point := before
+	point.Distance = distance
+	point.Time = before.Time + f*(after.Time-before.Time)
+	point.Speed = before.Speed + f*(after.Speed-before.Speed)
+	point.Throttle = before.Throttle + f*(after.Throttle-before.Throttle)
+	return point
+}
+
+// cornerDeltas finds corners as minima of the two laps' mean speed that sit
+// well below the speed on the approach, splits the lap halfway between
+// apexes and measures how the delta moved through each part
+func cornerDeltas(trace []ComparedPoint) []CornerDelta {
+	speed := func(i int) float64 { return (trace[i].SpeedA + trace[i].SpeedB) / 2 }
+
+	var apexes []int
+	approach := 0.0
+	for i := 1; i+1 < len(trace); i++ {
+		approach = math.Max(approach, speed(i))
+		if speed(i) > speed(i-1) || speed(i) > speed(i+1) || approach-speed(i) < minCornerSpeedDrop {
+			continue
+		}
+		if n := len(apexes); n > 0 && trace[i].Distance-trace[apexes[n-1]].Distance < minCornerSpacing {
+			if speed(i) < speed(apexes[n-1]) {
+				apexes[n-1] = i
+			}
+			continue
+		}
+		apexes = append(apexes, i)
+		approach = 0
+	}
+
+	corners := make([]CornerDelta, 0, len(apexes))
+	for n, apex := range apexes {
+		start, end := 0, len(trace)-1
+		if n > 0 {
+			start = (apexes[n-1] + apex) / 2
+		}
+		if n+1 < len(apexes) {
+			end = (apex + apexes[n+1]) / 2
+		}
+
+		corner := CornerDelta{
+			Number:    n + 1,
+			Apex:      trace[apex].Distance,
+			Start:     trace[start].Distance,
+			End:       trace[end].Distance,
+			MinSpeedA: trace[start].SpeedA,
+			MinSpeedB: trace[start].SpeedB,
+			TimeDelta: trace[end].Delta - trace[start].Delta,
+		}
+		for i := start; i <= end; i++ {
+			corner.MinSpeedA = math.Min(corner.MinSpeedA, trace[i].SpeedA)
+			corner.MinSpeedB = math.Min(corner.MinSpeedB, trace[i].SpeedB)
+		}
+		corners = append(corners, corner)
+	}
+	return corners
+}
@@ -0,0 +1,288 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/f1-analytics/models"
+)
+
+const (
+	// MaxExchangeGap is the widest gap, in seconds, at which two cars count as racing each other
+	MaxExchangeGap = 3.0
+	// MaxExchangeLaps is the most laps apart two stops can be and still form an exchange
+	MaxExchangeLaps = 5
+)
+
+// Pit exchange methods, named after what the car that started behind tried
+const (
+	MethodUndercut = "undercut"
+	MethodOvercut  = "overcut"
+)
+
+// PitExchange is a pair of stops between two cars running close together
+type PitExchange struct {
+	RaceID       uint    `json:"race_id"`
+	AttackerID   uint    `json:"attacker_id"` // The car that was behind before the stops
+	DefenderID   uint    `json:"defender_id"`
+	Method       string  `json:"method"` // undercut when the attacker stopped first, overcut otherwise
+	AttackerStop int     `json:"attacker_stop_lap"`
+	DefenderStop int     `json:"defender_stop_lap"`
+	GapBefore    float64 `json:"gap_before"` // Attacker's deficit before the first stop, seconds
+	GapAfter     float64 `json:"gap_after"`  // Attacker's deficit after the second stop, negative once ahead
+	TimeGained   float64 `json:"time_gained"`
+	Successful   bool    `json:"successful"`
+	Neutralised  bool    `json:"neutralised"` // A safety car ran during the exchange
+}
+
+// TeamPitExchanges aggregates a team's exchanges over a season
+type TeamPitExchanges struct {
+	TeamID            uint    `json:"team_id"`
+	UndercutAttempts  int     `json:"undercut_attempts"`
+	UndercutSuccesses int     `json:"undercut_successes"`
+	OvercutAttempts   int     `json:"overcut_attempts"`
+	OvercutSuccesses  int     `json:"overcut_successes"`
+	Defences          int     `json:"defences"`
+	DefenceSuccesses  int     `json:"defence_successes"`
+	TeammateExchanges int     `json:"teammate_exchanges"` // Exchanges between the team's own cars, left out of the counts above
+	NetTimeGained     float64 `json:"net_time_gained"`    // As attacker, less what was lost as defender
+}
+
+// raceTimeline holds each driver's elapsed race time at the end of every lap
+type raceTimeline struct {
+	elapsed     map[uint]map[int]float64
+	positions   map[uint]map[int]int
+	stops       map[uint][]int
+	neutralised map[int]bool
+}
+
+// buildTimeline works out when each driver completed every lap. A lap ends
+// when the next one starts, which also places the opening lap that OpenF1
+// publishes without a time. Where the next start is missing, the lap's time
+// is added to the end of the lap before. Elapsed times are measured from the
+// first lap start seen, so they only compare between drivers of one race.
+// Laps stored without start times fall back to lap one positions. Laps that
+// cannot be placed are left out without breaking the rest.
+func buildTimeline(laps []models.Lap) raceTimeline {
+	byDriver := make(map[uint]map[int]models.Lap)
+	var start time.Time
+	for _, lap := range laps {
+		if byDriver[lap.DriverID] == nil {
+			byDriver[lap.DriverID] = make(map[int]models.Lap)
+		}
+		byDriver[lap.DriverID][lap.LapNumber] = lap
+		if !lap.DateStart.IsZero() && (start.IsZero() || lap.DateStart.Before(start)) {
+			start = lap.DateStart
+		}
+	}
+	since := func(t time.Time) float64 { return t.Sub(start).Seconds() }
+
+	timeline := raceTimeline{
+		elapsed:     make(map[uint]map[int]float64),
+		positions:   make(map[uint]map[int]int),
+		stops:       make(map[uint][]int),
+		neutralised: make(map[int]bool),
+	}
+	for driverID, driverLaps := range byDriver {
+		numbers := make([]int, 0, len(driverLaps))
+		for number := range driverLaps {
+			numbers = append(numbers, number)
+		}
+		sort.Ints(numbers)
+		timeline.elapsed[driverID] = make(map[int]float64)
+		timeline.positions[driverID] = make(map[int]int)
+
+		for _, number := range numbers {
+			lap := driverLaps[number]
+			timeline.positions[driverID][number] = lap.Position
+			if lap.PitStop {
+				timeline.stops[driverID] = append(timeline.stops[driverID], number)
+			}
+			if lap.SafetyCar || lap.VirtualSafetyCar {
+				timeline.neutralised[number] = true
+			}
+
+			before, hasBefore := timeline.elapsed[driverID][number-1]
+			switch next, ok := driverLaps[number+1]; {
+			case ok && !next.DateStart.IsZero():
+				timeline.elapsed[driverID][number] = since(next.DateStart)
+			case lap.LapTime > 0 && hasBefore:
+				timeline.elapsed[driverID][number] = before + lap.LapTime.Seconds()
+			case lap.LapTime > 0 && !lap.DateStart.IsZero():
+				timeline.elapsed[driverID][number] = since(lap.DateStart) + lap.LapTime.Seconds()
+			case number == 1 && start.IsZero() && lap.LapTime > 0:
+				// Without any start times, the race starts the clock
+				timeline.elapsed[driverID][number] = lap.LapTime.Seconds()
+			case number == 1 && start.IsZero() && lap.Position > 0:
+				// Nothing times the opening lap, so cars are lined up in their
+				// order at its end, a millisecond apart
+				timeline.elapsed[driverID][number] = float64(lap.Position) / 1000
+			}
+		}
+	}
+	return timeline
+}
+
+// DetectPitExchanges finds every pair of stops, no more than MaxExchangeLaps
+// apart, between cars within MaxExchangeGap of each other, and decides
+// whether the car behind got ahead through them
+func DetectPitExchanges(raceID uint, laps []models.Lap) []PitExchange {
+	timeline := buildTimeline(laps)
+
+	drivers := make([]uint, 0, len(timeline.stops))
+	for driverID := range timeline.stops {
+		drivers = append(drivers, driverID)
+	}
+	sort.Slice(drivers, func(i, j int) bool { return drivers[i] < drivers[j] })
+
+	var exchanges []PitExchange
+	for _, first := range drivers {
+		for _, firstStop := range timeline.stops[first] {
+			for _, second := range drivers {
+				if second == first {
+					continue
+				}
+				for _, secondStop := range timeline.stops[second] {
+					if secondStop <= firstStop || secondStop-firstStop > MaxExchangeLaps {
+						continue
+					}
+					if exchange, ok := evaluateExchange(raceID, timeline, first, firstStop, second, secondStop); ok {
+						exchanges = append(exchanges, exchange)
+					}
+				}
+			}
+		}
+	}
+
+	sort.Slice(exchanges, func(i, j int) bool {
+		a, b := exchanges[i], exchanges[j]
+		if min(a.AttackerStop, a.DefenderStop) != min(b.AttackerStop, b.DefenderStop) {
+			return min(a.AttackerStop, a.DefenderStop) < min(b.AttackerStop, b.DefenderStop)
+		}
+		return a.AttackerID < b.AttackerID
+	})
+	return exchanges
+}
+
+// evaluateExchange measures the gap before the first car stopped and after
+// the second car's out-lap
+func evaluateExchange(raceID uint, timeline raceTimeline, first uint, firstStop int, second uint, secondStop int) (PitExchange, bool) {
+	beforeLap := firstStop - 1
+	afterLap := secondStop + 1
+
+	firstBefore, ok1 := timeline.elapsed[first][beforeLap]
+	secondBefore, ok2 := timeline.elapsed[second][beforeLap]
+	firstAfter, ok3 := timeline.elapsed[first][afterLap]
+	secondAfter, ok4 := timeline.elapsed[second][afterLap]
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		return PitExchange{}, false
+	}
+
+	// Positive while the first stopper is behind the second
+	gapBefore := firstBefore - secondBefore
+	if math.Abs(gapBefore) > MaxExchangeGap || gapBefore == 0 {
+		return PitExchange{}, false
+	}
+	gapAfter := firstAfter - secondAfter
+
+	// Another stop by either car inside the window muddies the comparison
+	for _, driverID := range []uint{first, second} {
+		for _, stop := range timeline.stops[driverID] {
+			if stop > beforeLap && stop < afterLap && stop != firstStop && stop != secondStop {
+				return PitExchange{}, false
+			}
+		}
+	}
+
+	exchange := PitExchange{RaceID: raceID}
+	for lap := beforeLap; lap <= afterLap; lap++ {
+		if timeline.neutralised[lap] {
+			exchange.Neutralised = true
+		}
+	}
+
+	if gapBefore > 0 {
+		exchange.AttackerID, exchange.DefenderID = first, second
+		exchange.AttackerStop, exchange.DefenderStop = firstStop, secondStop
+		exchange.Method = MethodUndercut
+		exchange.GapBefore, exchange.GapAfter = gapBefore, gapAfter
+	} else {
+		exchange.AttackerID, exchange.DefenderID = second, first
+		exchange.AttackerStop, exchange.DefenderStop = secondStop, firstStop
+		exchange.Method = MethodOvercut
+		exchange.GapBefore, exchange.GapAfter = -gapBefore, -gapAfter
+	}
+	exchange.TimeGained = exchange.GapBefore - exchange.GapAfter
+
+	// Prefer the timing screen's order when it was recorded
+	attackerPos := timeline.positions[exchange.AttackerID][afterLap]
+	defenderPos := timeline.positions[exchange.DefenderID][afterLap]
+	if attackerPos > 0 && defenderPos > 0 {
+		exchange.Successful = attackerPos < defenderPos
+	} else {
+		exchange.Successful = exchange.GapAfter < 0
+	}
+
+	return exchange, true
+}
+
+// AggregatePitExchanges totals exchanges per team. teamOf resolves the team a
+// driver raced for in a given race. An exchange between teammates is counted
+// once on its own, as the team both won and lost it.
+func AggregatePitExchanges(exchanges []PitExchange, teamOf func(raceID, driverID uint) uint) []TeamPitExchanges {
+	byTeam := make(map[uint]*TeamPitExchanges)
+	team := func(id uint) *TeamPitExchanges {
+		entry, ok := byTeam[id]
+		if !ok {
+			entry = &TeamPitExchanges{TeamID: id}
+			byTeam[id] = entry
+		}
+		return entry
+	}
+
+	for _, exchange := range exchanges {
+		attackerTeam := teamOf(exchange.RaceID, exchange.AttackerID)
+		defenderTeam := teamOf(exchange.RaceID, exchange.DefenderID)
+
+		if attackerTeam != 0 && attackerTeam == defenderTeam {
+			team(attackerTeam).TeammateExchanges++
+			continue
+		}
+		if attackerTeam != 0 {
+			attacker := team(attackerTeam)
+			if exchange.Method == MethodUndercut {
+				attacker.UndercutAttempts++
+				if exchange.Successful {
+					attacker.UndercutSuccesses++
+				}
+			} else {
+				attacker.OvercutAttempts++
+				if exchange.Successful {
+					attacker.OvercutSuccesses++
+				}
+			}
+			attacker.NetTimeGained += exchange.TimeGained
+		}
+		if defenderTeam != 0 {
+			defender := team(defenderTeam)
+			defender.Defences++
+			if !exchange.Successful {
+				defender.DefenceSuccesses++
+			}
+			defender.NetTimeGained -= exchange.TimeGained
+		}
+	}
+
+	teams := make([]TeamPitExchanges, 0, len(byTeam))
+	for _, entry := range byTeam {
+		teams = append(teams, *entry)
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		if teams[i].NetTimeGained != teams[j].NetTimeGained {
+			return teams[i].NetTimeGained > teams[j].NetTimeGained
+		}
+		return teams[i].TeamID < teams[j].TeamID
+	})
+	return teams
+}